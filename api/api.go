@@ -0,0 +1,205 @@
+// Package api serves read-only JSON API over the bot's storage, e.g. for
+// dashboards. Every request but the OpenAPI description needs
+// "Authorization: Bearer <API_TOKEN>".
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maddevsio/punisher/model"
+	"github.com/maddevsio/punisher/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// Limits of standups pages
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+// Server is the REST API
+type Server struct {
+	db    storage.Store
+	token string
+	mux   *http.ServeMux
+}
+
+// New creates API over db, clients authenticate with token
+func New(db storage.Store, token string) *Server {
+	s := &Server{db: db, token: token, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/api/openapi.yaml", s.openAPI)
+	s.mux.HandleFunc("/api/groups", s.authorized(s.groups))
+	s.mux.HandleFunc("/api/groups/", s.authorized(s.groupInterns))
+	s.mux.HandleFunc("/api/standups", s.authorized(s.standups))
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// authorized lets through GET requests with the bearer token
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(header, "Bearer ")
+		if s.token == "" || token == header || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "API is read-only")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// groups handles GET /api/groups. Groups that never changed their settings
+// have only ID, zero and nil settings mean defaults from the environment.
+func (s *Server) groups(w http.ResponseWriter, r *http.Request) {
+	ids, err := s.db.ListGroups()
+	if err != nil {
+		writeInternal(w, "ListGroups", err)
+		return
+	}
+	groups := []model.Group{}
+	for _, id := range ids {
+		group, err := s.db.SelectGroup(id)
+		if err == sql.ErrNoRows {
+			group = model.Group{ID: id}
+		} else if err != nil {
+			writeInternal(w, "SelectGroup", err)
+			return
+		}
+		groups = append(groups, group)
+	}
+	writeJSON(w, http.StatusOK, groups)
+}
+
+// groupInterns handles GET /api/groups/{id}/interns
+func (s *Server) groupInterns(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/groups/"), "/")
+	if len(parts) != 2 || parts[1] != "interns" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	groupID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "group ID must be a number")
+		return
+	}
+	interns, err := s.db.ListGroupInterns(groupID)
+	if err != nil {
+		writeInternal(w, "ListGroupInterns", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, interns)
+}
+
+// standupsPage is a page of standups, the next one starts at Offset+Limit
+// when HasMore is set
+type standupsPage struct {
+	Standups []model.Standup `json:"standups"`
+	Limit    int             `json:"limit"`
+	Offset   int             `json:"offset"`
+	HasMore  bool            `json:"hasMore"`
+}
+
+// standups handles GET /api/standups?user=&group=&since=&until=&limit=&offset=
+func (s *Server) standups(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := f.Limit
+	// one more standup tells whether there is the next page
+	f.Limit++
+	items, err := s.db.FindStandups(f)
+	if err != nil {
+		writeInternal(w, "FindStandups", err)
+		return
+	}
+	page := standupsPage{Standups: items, Limit: limit, Offset: f.Offset}
+	if len(items) > limit {
+		page.Standups, page.HasMore = items[:limit], true
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// parseFilter reads standups filter from query. Dates are YYYY-MM-DD in UTC
+// or RFC 3339 times, until date is inclusive.
+func parseFilter(r *http.Request) (storage.StandupFilter, error) {
+	q := r.URL.Query()
+	f := storage.StandupFilter{Username: strings.TrimPrefix(q.Get("user"), "@"), Limit: defaultLimit}
+	var err error
+	if v := q.Get("group"); v != "" {
+		if f.GroupID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return f, errors.New("group must be a number")
+		}
+	}
+	if v := q.Get("since"); v != "" {
+		if f.Since, err = parseTime(v, false); err != nil {
+			return f, errors.New("since must be YYYY-MM-DD or RFC 3339 time")
+		}
+	}
+	if v := q.Get("until"); v != "" {
+		if f.Until, err = parseTime(v, true); err != nil {
+			return f, errors.New("until must be YYYY-MM-DD or RFC 3339 time")
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit < 1 || f.Limit > maxLimit {
+			return f, fmt.Errorf("limit must be from 1 to %d", maxLimit)
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if f.Offset, err = strconv.Atoi(v); err != nil || f.Offset < 0 {
+			return f, errors.New("offset must be a non-negative number")
+		}
+	}
+	return f, nil
+}
+
+// parseTime parses date or time, end of day is the start of the next one
+func parseTime(s string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return t, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.Errorf("encoding API response failed: %v\n", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeInternal(w http.ResponseWriter, op string, err error) {
+	logrus.Errorf("%s failed: %v\n", op, err)
+	writeError(w, http.StatusInternalServerError, "storage error")
+}
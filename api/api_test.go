@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maddevsio/punisher/model"
+	"github.com/maddevsio/punisher/storage"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+const token = "secret"
+
+func get(t *testing.T, s *Server, url, token string, v interface{}) int {
+	r := httptest.NewRequest(http.MethodGet, url, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if v != nil {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), v), w.Body.String())
+	}
+	return w.Code
+}
+
+func setupAPI(t *testing.T) *Server {
+	db := storage.NewMemory()
+	db.CreateGroup(model.Group{ID: -100, PunishTime: "09:00"})
+	db.CreateIntern(model.Intern{Username: "alice", GroupID: -100, Lives: 3})
+	db.CreateIntern(model.Intern{Username: "bob", GroupID: -200, Lives: 1})
+	for _, s := range []model.Standup{
+		{Username: "alice", GroupID: -100, Comment: "1"},
+		{Username: "bob", GroupID: -200, Comment: "2"},
+		{Username: "alice", GroupID: -100, Comment: "3"},
+	} {
+		_, err := db.CreateStandup(s)
+		assert.NoError(t, err)
+	}
+	return New(db, token)
+}
+
+func TestAuth(t *testing.T) {
+	s := setupAPI(t)
+	var e map[string]string
+	assert.Equal(t, http.StatusUnauthorized, get(t, s, "/api/groups", "", &e))
+	assert.Equal(t, "invalid token", e["error"])
+	assert.Equal(t, http.StatusUnauthorized, get(t, s, "/api/groups", "wrong", nil))
+
+	r := httptest.NewRequest(http.MethodGet, "/api/groups", nil)
+	r.Header.Set("Authorization", token)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	r = httptest.NewRequest(http.MethodPost, "/api/groups", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestGroupsAndInterns(t *testing.T) {
+	s := setupAPI(t)
+	var groups []model.Group
+	assert.Equal(t, http.StatusOK, get(t, s, "/api/groups", token, &groups))
+	assert.Equal(t, []model.Group{{ID: -200}, {ID: -100, PunishTime: "09:00"}}, groups)
+
+	var interns []map[string]interface{}
+	assert.Equal(t, http.StatusOK, get(t, s, "/api/groups/-100/interns", token, &interns))
+	assert.Equal(t, 1, len(interns))
+	assert.Equal(t, "alice", interns[0]["userName"])
+	assert.Equal(t, float64(3), interns[0]["lives"])
+
+	assert.Equal(t, http.StatusBadRequest, get(t, s, "/api/groups/abc/interns", token, nil))
+	assert.Equal(t, http.StatusNotFound, get(t, s, "/api/groups/-100/standups", token, nil))
+}
+
+func TestStandups(t *testing.T) {
+	s := setupAPI(t)
+	comments := func(page standupsPage) []string {
+		res := []string{}
+		for _, s := range page.Standups {
+			res = append(res, s.Comment)
+		}
+		return res
+	}
+	var page standupsPage
+	assert.Equal(t, http.StatusOK, get(t, s, "/api/standups", token, &page))
+	assert.Equal(t, []string{"1", "2", "3"}, comments(page))
+	assert.False(t, page.HasMore)
+
+	page = standupsPage{}
+	assert.Equal(t, http.StatusOK, get(t, s, "/api/standups?user=@alice&group=-100&limit=1", token, &page))
+	assert.Equal(t, []string{"1"}, comments(page))
+	assert.True(t, page.HasMore)
+	page = standupsPage{}
+	get(t, s, "/api/standups?user=alice&limit=1&offset=1", token, &page)
+	assert.Equal(t, []string{"3"}, comments(page))
+	assert.Equal(t, 1, page.Offset)
+	assert.False(t, page.HasMore)
+
+	page = standupsPage{}
+	get(t, s, "/api/standups?since=2000-01-01&until=2000-01-31", token, &page)
+	assert.Equal(t, []string{}, comments(page))
+
+	var e map[string]string
+	assert.Equal(t, http.StatusBadRequest, get(t, s, "/api/standups?since=yesterday", token, &e))
+	assert.Equal(t, "since must be YYYY-MM-DD or RFC 3339 time", e["error"])
+	assert.Equal(t, http.StatusBadRequest, get(t, s, "/api/standups?limit=1000", token, nil))
+}
+
+func TestOpenAPI(t *testing.T) {
+	s := setupAPI(t)
+	r := httptest.NewRequest(http.MethodGet, "/api/openapi.yaml", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var spec struct {
+		Paths map[string]interface{} `yaml:"paths"`
+	}
+	assert.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Contains(t, spec.Paths, "/api/groups")
+	assert.Contains(t, spec.Paths, "/api/groups/{id}/interns")
+	assert.Contains(t, spec.Paths, "/api/standups")
+}
@@ -0,0 +1,239 @@
+package api
+
+import "net/http"
+
+// openAPIYAML describes the API, it is served at /api/openapi.yaml
+const openAPIYAML = `openapi: 3.0.3
+info:
+  title: Punisher API
+  description: Read-only access to groups, interns and standups of the punisher bot.
+  version: 1.0.0
+security:
+  - bearer: []
+paths:
+  /api/groups:
+    get:
+      summary: List groups
+      description: Groups that never changed their settings have only id, zero and null settings mean defaults from the environment.
+      responses:
+        "200":
+          description: Groups
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Group"
+        "401":
+          $ref: "#/components/responses/Unauthorized"
+  /api/groups/{id}/interns:
+    get:
+      summary: List interns of a group
+      parameters:
+        - name: id
+          in: path
+          required: true
+          description: Telegram chat ID of the group
+          schema:
+            type: integer
+            format: int64
+      responses:
+        "200":
+          description: Interns
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Intern"
+        "400":
+          $ref: "#/components/responses/BadRequest"
+        "401":
+          $ref: "#/components/responses/Unauthorized"
+  /api/standups:
+    get:
+      summary: List standups, oldest first
+      parameters:
+        - name: user
+          in: query
+          description: Telegram username, with or without @
+          schema:
+            type: string
+        - name: group
+          in: query
+          description: Telegram chat ID of the group
+          schema:
+            type: integer
+            format: int64
+        - name: since
+          in: query
+          description: First day (YYYY-MM-DD, UTC) or RFC 3339 time, inclusive
+          schema:
+            type: string
+        - name: until
+          in: query
+          description: Last day (YYYY-MM-DD, UTC), inclusive, or RFC 3339 time, exclusive
+          schema:
+            type: string
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            minimum: 1
+            maximum: 500
+            default: 50
+        - name: offset
+          in: query
+          schema:
+            type: integer
+            minimum: 0
+            default: 0
+      responses:
+        "200":
+          description: Page of standups, the next one starts at offset + limit when hasMore is true
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  standups:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/Standup"
+                  limit:
+                    type: integer
+                  offset:
+                    type: integer
+                  hasMore:
+                    type: boolean
+        "400":
+          $ref: "#/components/responses/BadRequest"
+        "401":
+          $ref: "#/components/responses/Unauthorized"
+components:
+  securitySchemes:
+    bearer:
+      type: http
+      scheme: bearer
+      description: API_TOKEN of the bot
+  responses:
+    BadRequest:
+      description: Invalid parameter
+      content:
+        application/json:
+          schema:
+            $ref: "#/components/schemas/Error"
+    Unauthorized:
+      description: Missing or invalid token
+      content:
+        application/json:
+          schema:
+            $ref: "#/components/schemas/Error"
+  schemas:
+    Error:
+      type: object
+      properties:
+        error:
+          type: string
+    Group:
+      type: object
+      properties:
+        id:
+          type: integer
+          format: int64
+        punishTime:
+          type: string
+          example: "10:00"
+        punishmentType:
+          type: string
+        notifyMentors:
+          type: boolean
+          nullable: true
+        mentorsChat:
+          type: integer
+          format: int64
+        lives:
+          type: integer
+        timezone:
+          type: string
+        workdays:
+          type: string
+          example: пн,вт,ср,чт,пт
+        reminders:
+          type: string
+          example: 60,15
+        remindPrivately:
+          type: boolean
+          nullable: true
+        streakBonus:
+          type: integer
+        maxLives:
+          type: integer
+        languages:
+          type: string
+          example: ru,en
+        locale:
+          type: string
+        sharedStandups:
+          type: boolean
+          nullable: true
+    Intern:
+      type: object
+      properties:
+        id:
+          type: integer
+          format: int64
+        userName:
+          type: string
+        lives:
+          type: integer
+        groupid:
+          type: integer
+          format: int64
+        userid:
+          type: integer
+          format: int64
+          description: Telegram user ID, 0 until the intern is seen in the group
+        streak:
+          type: integer
+        checks:
+          type: integer
+        ontime:
+          type: integer
+    Standup:
+      type: object
+      properties:
+        id:
+          type: integer
+          format: int64
+        created:
+          type: string
+          format: date-time
+        modified:
+          type: string
+          format: date-time
+        userName:
+          type: string
+        comment:
+          type: string
+        groupid:
+          type: integer
+          format: int64
+        yesterday:
+          type: string
+        today:
+          type: string
+        blockers:
+          type: string
+        chatid:
+          type: integer
+          format: int64
+        messageid:
+          type: integer
+`
+
+// openAPI serves the API description, it needs no token
+func (s *Server) openAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	w.Write([]byte(openAPIYAML))
+}
@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"strings"
+	"time"
+
+	"github.com/maddevsio/punisher/calendar"
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/model"
+	"github.com/sirupsen/logrus"
+)
+
+// absenceMessages map absence kinds to the way bot talks about them
+var absenceMessages = map[string]string{
+	model.AbsenceVacation: msgAbsenceVacation,
+	model.AbsenceSick:     msgAbsenceSick,
+}
+
+// absenceCommand handles "отпуск @user <с> <по>" and "болеет @user [по]"
+func (b *Bot) absenceCommand(channel int64, command string, args []string) {
+	today := calendar.Day(b.now().In(b.location(b.groupSettings(channel))))
+	var kind string
+	var since, until time.Time
+	var err error
+	switch command {
+	case "отпуск":
+		if len(args) < 3 {
+			b.say(channel, msgAbsenceVacationFormat, nil)
+			return
+		}
+		kind = model.AbsenceVacation
+		since, err = calendar.ParseDate(args[1])
+		if err == nil {
+			until, err = calendar.ParseDate(args[2])
+		}
+	case "болеет":
+		if len(args) < 1 {
+			b.say(channel, msgAbsenceSickFormat, nil)
+			return
+		}
+		kind, since, until = model.AbsenceSick, today, today
+		if len(args) > 1 {
+			until, err = calendar.ParseDate(args[1])
+		}
+	}
+	if err != nil {
+		b.say(channel, msgDateFormat, nil)
+		return
+	}
+	if until.Before(since) {
+		b.say(channel, msgAbsenceOrder, nil)
+		return
+	}
+	username := strings.Replace(args[0], "@", "", -1)
+	intern, err := b.db.FindIntern(username, channel)
+	if err != nil {
+		b.say(channel, msgAbsenceUnknown, i18n.Vars{"user": username})
+		return
+	}
+	absence, err := b.db.CreateAbsence(model.Absence{
+		InternID: intern.ID,
+		GroupID:  channel,
+		Kind:     kind,
+		Since:    since,
+		Until:    until,
+	})
+	if err != nil {
+		logrus.Errorf("CreateAbsence failed: %v\n", err)
+		b.say(channel, msgAbsenceNotSaved, nil)
+		return
+	}
+	b.say(channel, msgAbsenceSaved, i18n.Vars{"absence": b.formatAbsence(intern, absence)})
+}
+
+// returnedCommand handles "вернулся @user" ending current and upcoming absences
+func (b *Bot) returnedCommand(channel int64, args []string) {
+	if len(args) == 0 {
+		b.say(channel, msgAbsenceReturnedFormat, nil)
+		return
+	}
+	username := strings.Replace(args[0], "@", "", -1)
+	intern, err := b.db.FindIntern(username, channel)
+	if err != nil {
+		b.say(channel, msgAbsenceUnknown, i18n.Vars{"user": username})
+		return
+	}
+	absences, err := b.db.ListAbsences(channel)
+	if err != nil {
+		logrus.Errorf("ListAbsences failed: %v\n", err)
+		return
+	}
+	today := calendar.Day(b.now().In(b.location(b.groupSettings(channel))))
+	yesterday := today.AddDate(0, 0, -1)
+	ended := false
+	for _, a := range absences {
+		if a.InternID != intern.ID || a.Until.Before(today) {
+			continue
+		}
+		ended = true
+		if a.Since.After(yesterday) {
+			err = b.db.DeleteAbsence(a.ID)
+		} else {
+			a.Until = yesterday
+			_, err = b.db.UpdateAbsence(a)
+		}
+		if err != nil {
+			logrus.Errorf("ending absence failed: %v\n", err)
+			return
+		}
+	}
+	if !ended {
+		b.say(channel, msgAbsenceNotAway, i18n.Vars{"user": intern.Username})
+		return
+	}
+	b.say(channel, msgAbsenceReturned, i18n.Vars{"user": intern.Username})
+}
+
+// absencesFrom returns absences of the group that end on day or later,
+// keyed by intern
+func (b *Bot) absencesFrom(groupID int64, day time.Time) (map[int64][]model.Absence, error) {
+	absences, err := b.db.ListAbsences(groupID)
+	if err != nil {
+		return nil, err
+	}
+	byIntern := map[int64][]model.Absence{}
+	for _, a := range absences {
+		if !a.Until.Before(day) {
+			byIntern[a.InternID] = append(byIntern[a.InternID], a)
+		}
+	}
+	return byIntern, nil
+}
+
+// activeAbsence returns absence that covers day
+func activeAbsence(absences []model.Absence, day time.Time) (model.Absence, bool) {
+	for _, a := range absences {
+		if !day.Before(a.Since) && !day.After(a.Until) {
+			return a, true
+		}
+	}
+	return model.Absence{}, false
+}
+
+// formatAbsence describes absence, e.g. "@user в отпуске с 2026-10-20 по 2026-10-27"
+func (b *Bot) formatAbsence(intern model.Intern, a model.Absence) string {
+	return b.t(intern.GroupID, absenceMessages[a.Kind], i18n.Vars{
+		"user":  intern.Username,
+		"since": a.Since.Format("2006-01-02"),
+		"until": a.Until.Format("2006-01-02"),
+	})
+}
+
+// formatAbsences lists current and upcoming absences of interns
+func (b *Bot) formatAbsences(interns []model.Intern, absences map[int64][]model.Absence) []string {
+	lines := []string{}
+	for _, intern := range interns {
+		for _, a := range absences[intern.ID] {
+			lines = append(lines, b.formatAbsence(intern, a))
+		}
+	}
+	return lines
+}
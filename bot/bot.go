@@ -8,11 +8,16 @@ import (
 	"math/rand"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jasonlvhit/gocron"
+	"github.com/maddevsio/punisher/calendar"
 	"github.com/maddevsio/punisher/config"
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/metrics"
 	"github.com/maddevsio/punisher/model"
+	"github.com/maddevsio/punisher/standup"
 	"github.com/maddevsio/punisher/storage"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/telegram-bot-api.v4"
@@ -22,43 +27,183 @@ const (
 	telegramAPIUpdateInterval = 60
 )
 
+var errDayOff = errors.New("day off")
+
+// Messenger is the part of Telegram Bot API the bot relies on.
+// *tgbotapi.BotAPI implements it.
+type Messenger interface {
+	GetMe() (tgbotapi.User, error)
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	GetChatAdministrators(config tgbotapi.ChatConfig) ([]tgbotapi.ChatMember, error)
+	KickChatMember(config tgbotapi.KickChatMemberConfig) (tgbotapi.APIResponse, error)
+	GetUpdates(config tgbotapi.UpdateConfig) ([]tgbotapi.Update, error)
+	AnswerCallbackQuery(config tgbotapi.CallbackConfig) (tgbotapi.APIResponse, error)
+}
+
 // Bot ...
 type Bot struct {
 	c       *config.BotConfig
-	tgAPI   *tgbotapi.BotAPI
-	updates tgbotapi.UpdatesChannel
-	db      *storage.MySQL
+	tgAPI   Messenger
+	self    tgbotapi.User
+	updates chan tgbotapi.Update
+	db      storage.Store
+	// lastPoll and lastTick are unix nanoseconds of the last successful
+	// getUpdates and the last scheduleChecks, see CheckPolling and CheckScheduler
+	lastPoll int64
+	lastTick int64
+	// loc is default timezone for groups without their own
+	loc *time.Location
+	// holidays are days off for every group
+	holidays *calendar.Calendar
+	// lastCheck maps group ID to the date its daily check last ran
+	lastCheck map[int64]string
+	// prevTick is the time of the previous scheduleChecks
+	prevTick time.Time
+	// lastReminder maps "group ID/minutes" to the date of the deadline the
+	// reminder was sent for
+	lastReminder map[string]string
+	// lastDigest maps group ID to the date its weekly digest was sent
+	lastDigest map[int64]string
+	// digestDay and digestAt schedule weekly digests, digestAt is empty when
+	// they are disabled
+	digestDay   time.Weekday
+	digestAt    string
+	punishments *Registry
+	// languages are standup language packs groups choose from
+	languages standup.Languages
+	catalog   *i18n.Catalog
+	// now tells the time to scheduled checks and commands, see WithClock
+	now func() time.Time
+}
+
+// Option configures optional Bot dependencies
+type Option func(*Bot)
+
+// WithStore makes bot use s instead of the storage selected by DatabaseURL
+func WithStore(s storage.Store) Option {
+	return func(b *Bot) {
+		b.db = s
+	}
+}
+
+// WithMessenger makes bot talk to Telegram through m, e.g. a client
+// of telegramtest.Server
+func WithMessenger(m Messenger) Option {
+	return func(b *Bot) {
+		b.tgAPI = m
+	}
+}
+
+// WithPunishment registers p so that groups can choose it, see Registry.Register
+func WithPunishment(p Punishment, weight int) Option {
+	return func(b *Bot) {
+		b.punishments.Register(p, weight)
+	}
+}
+
+// WithClock makes bot take the current time from now instead of time.Now,
+// e.g. a clock tests move by hand
+func WithClock(now func() time.Time) Option {
+	return func(b *Bot) {
+		b.now = now
+	}
 }
 
 // NewTGBot creates a new bot
-func NewTGBot(c *config.BotConfig) (*Bot, error) {
-	newBot, err := tgbotapi.NewBotAPI(c.TelegramToken)
+func NewTGBot(c *config.BotConfig, opts ...Option) (*Bot, error) {
+	loc, err := time.LoadLocation(c.Timezone)
 	if err != nil {
 		return nil, err
 	}
-	b := &Bot{
-		c:     c,
-		tgAPI: newBot,
+	if _, err := calendar.ParseWeekdays(c.Workdays); err != nil {
+		return nil, fmt.Errorf("invalid WORKDAYS: %v", err)
 	}
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = telegramAPIUpdateInterval
-	updates, err := b.tgAPI.GetUpdatesChan(u)
+	if _, err := parseReminders(c.Reminders); err != nil {
+		return nil, fmt.Errorf("invalid REMINDERS: %v", err)
+	}
+	digestDay, digestAt, err := parseDigest(c.Digest)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid DIGEST: %v", err)
 	}
-	conn, err := storage.NewMySQL(c)
+	languages, err := standup.NewLanguages(c.LanguagesDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LANGUAGES_DIR: %v", err)
+	}
+	if _, err := languages.ParseCodes(c.Languages); err != nil {
+		return nil, fmt.Errorf("invalid LANGUAGES: %v", err)
+	}
+	var holidays *calendar.Calendar
+	if c.HolidaysFile != "" {
+		if holidays, err = calendar.Load(c.HolidaysFile); err != nil {
+			return nil, err
+		}
+		logrus.Infof("Loaded %d holidays from %s\n", len(holidays.Holidays), c.HolidaysFile)
+	}
+	b := &Bot{
+		c:            c,
+		loc:          loc,
+		holidays:     holidays,
+		lastCheck:    map[int64]string{},
+		lastReminder: map[string]string{},
+		lastDigest:   map[int64]string{},
+		digestDay:    digestDay,
+		digestAt:     digestAt,
+		punishments:  NewRegistry(),
+		languages:    languages,
+		catalog:      newCatalog(),
+		now:          time.Now,
+	}
+	if !b.catalog.HasLocale(c.Locale) {
+		return nil, fmt.Errorf("invalid LOCALE: choose one of %s", strings.Join(b.catalog.Locales(), ", "))
+	}
+	b.punishments.Register(pushUps, 1)
+	b.punishments.Register(snowFlakes, 1)
+	b.punishments.Register(lives{b}, 1)
+	b.punishments.Register(sitUps, 1)
+	b.punishments.Register(poetry{b}, 1)
+	for _, opt := range opts {
+		opt(b)
+	}
+	if err := b.punishments.SetWeights(c.PunishmentWeights); err != nil {
+		return nil, fmt.Errorf("invalid PUNISHMENT_WEIGHTS: %v", err)
+	}
+	if _, ok := b.punishments.Get(c.PunishmentType); !ok && c.PunishmentType != randomPunishment {
+		return nil, fmt.Errorf("invalid PUNISHMENT_TYPE: unknown punishment %q", c.PunishmentType)
+	}
+	if b.tgAPI == nil {
+		newBot, err := tgbotapi.NewBotAPI(c.TelegramToken)
+		if err != nil {
+			return nil, err
+		}
+		b.tgAPI = newBot
+	}
+	b.tgAPI = countingMessenger{b.tgAPI}
+	self, err := b.tgAPI.GetMe()
 	if err != nil {
 		return nil, err
 	}
-	b.updates = updates
-	b.db = conn
-	gocron.Every(1).Day().At(c.PunishTime).Do(b.dailyJob)
+	b.self = self
+	if b.db == nil {
+		conn, err := storage.New(c)
+		if err != nil {
+			return nil, err
+		}
+		b.db = conn
+	}
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = telegramAPIUpdateInterval
+	b.updates = make(chan tgbotapi.Update, 100)
+	// the first poll may take the whole timeout
+	b.touch(&b.lastPoll)
+	go b.poll(u)
+	gocron.Every(1).Minute().Do(b.scheduleChecks)
 
 	return b, nil
 }
 
 // Start ...
 func (b *Bot) Start() {
+	b.touch(&b.lastTick)
 	go func() {
 		<-gocron.Start()
 	}()
@@ -69,16 +214,28 @@ func (b *Bot) Start() {
 }
 
 func (b *Bot) handleUpdate(update tgbotapi.Update) {
-
+	metrics.UpdatesReceived.Inc()
+	if update.CallbackQuery != nil {
+		b.handleCallback(update.CallbackQuery)
+		return
+	}
+	if update.EditedMessage != nil {
+		b.handleEdit(update.EditedMessage)
+		return
+	}
 	if update.Message == nil {
 		return
 	}
-	text := update.Message.Text
+	b.trackInterns(update.Message)
+	if b.handleProof(update.Message) {
+		return
+	}
+	text := standupText(update.Message)
 	if text == "" || text == "/start" {
 		return
 	}
 
-	if !strings.Contains(text, "@"+b.tgAPI.Self.UserName) {
+	if !strings.Contains(text, "@"+b.self.UserName) {
 		return
 	}
 
@@ -86,116 +243,156 @@ func (b *Bot) handleUpdate(update tgbotapi.Update) {
 
 	logrus.Infof("New MSG from [%v] chat [%v]\n", update.Message.From.UserName, channel)
 
-	isAdmin, err := b.senderIsAdminInChannel(update.Message.From.UserName, channel)
+	isAdmin, err := b.senderIsAdminInChannel(update.Message.From, channel)
 	if err != nil {
 		logrus.Errorf("senderIsAdminInChannel func failed: [%v]\n", err)
 	}
 
-	s := strings.Split(update.Message.Text, " ")
-	if s[0] == "@"+b.tgAPI.Self.UserName && (s[1] == "добавь" || s[1] == "удали") && s[2] != "" && isAdmin {
-		switch c := s[1]; c {
-		case "добавь":
-			logrus.Infof("Add intern: %s to DB\n", s[2])
-			username := strings.Replace(s[2], "@", "", -1)
-			intern := model.Intern{0, username, 3, channel}
-			_, err := b.db.FindIntern(username, channel)
-			if err != nil {
-				_, err := b.db.CreateIntern(intern)
-				if err != nil {
-					logrus.Errorf("CreateIntern failed: %v", err)
-					b.tgAPI.Send(tgbotapi.NewMessage(channel, fmt.Sprintf("не буду следить за @%s", intern.Username)))
-					return
-				}
-				b.tgAPI.Send(tgbotapi.NewMessage(channel, fmt.Sprintf("@%s, я слежу за тобой.", intern.Username)))
-			} else {
-				b.tgAPI.Send(tgbotapi.NewMessage(channel, fmt.Sprintf("Уже слежу за @%s, зачем 2 раза просить?", intern.Username)))
-			}
-
-		case "удали":
-			logrus.Infof("Remove intern: %s from DB\n", s[2])
-			username := strings.Replace(s[2], "@", "", -1)
-			intern, err := b.db.FindIntern(username, channel)
-			if err != nil {
-				logrus.Errorf("FindIntern failed: %v", err)
-				b.tgAPI.Send(tgbotapi.NewMessage(channel, fmt.Sprintf("да я и не следил за @%s, а надо было?", username)))
-				return
-			}
-			err = b.db.DeleteIntern(intern.ID)
-			if err != nil {
-				logrus.Errorf("DeleteIntern failed: %v", err)
-				b.tgAPI.Send(tgbotapi.NewMessage(channel, fmt.Sprintf("мне @%s очень нравится... Дальше послежу!", intern.Username)))
-				return
-			}
-			b.tgAPI.Send(tgbotapi.NewMessage(channel, fmt.Sprintf("@%s, я больше не слежу за тобой.", intern.Username)))
-		}
+	if b.handleCommand(update.Message, isAdmin) {
+		return
+	}
+	if b.isStandup(update.Message) {
+		b.acceptStandup(update.Message)
 	} else {
-		if b.isStandup(update.Message) {
-			logrus.Infof("accepted standup from %s\n", update.Message.From.UserName)
-			standup := model.Standup{
-				Comment:  update.Message.Text,
-				Username: update.Message.From.UserName,
-			}
-			_, err := b.db.CreateStandup(standup)
-			if err != nil {
-				logrus.Errorf("CreateStandup failed: %v\n", err)
-				b.tgAPI.Send(tgbotapi.NewMessage(channel, fmt.Sprintf("@%s у тебя кажется норм стендап, но сохранять его не буду.", update.Message.From.UserName)))
-				return
-			}
-			b.tgAPI.Send(tgbotapi.NewMessage(channel, fmt.Sprintf("@%s спасибо. Я принял твой стендап", update.Message.From.UserName)))
-
-			if b.c.NotifyMentors {
-				b.tgAPI.Send(tgbotapi.ForwardConfig{
-					FromChannelUsername: update.Message.From.UserName,
-					FromChatID:          channel,
-					MessageID:           update.Message.MessageID,
-					BaseChat:            tgbotapi.BaseChat{ChatID: b.c.MentorsChat},
-				})
-			}
+		b.explainRejected(update.Message)
+	}
+}
 
+// acceptStandup saves standup and forwards it to mentors if the group wants it
+func (b *Bot) acceptStandup(message *tgbotapi.Message) {
+	channel := message.Chat.ID
+	logrus.Infof("accepted standup from %s\n", message.From.UserName)
+	saved, err := b.db.CreateStandup(b.parseStandup(message))
+	if err != nil {
+		logrus.Errorf("CreateStandup failed: %v\n", err)
+		metrics.StandupsRejected.WithLabelValues("not_saved").Inc()
+		b.tgAPI.Send(tgbotapi.NewMessage(channel, b.t(channel, msgStandupNotSaved, i18n.Vars{"user": message.From.UserName})))
+		return
+	}
+	for _, a := range attachments(message) {
+		a.StandupID = saved.ID
+		if _, err := b.db.CreateAttachment(a); err != nil {
+			logrus.Errorf("CreateAttachment failed: %v\n", err)
 		}
 	}
+	metrics.StandupsAccepted.Inc()
+	b.tgAPI.Send(tgbotapi.NewMessage(channel, b.t(channel, msgStandupAccepted, i18n.Vars{"user": message.From.UserName})))
+
+	group := b.groupSettings(channel)
+	if enabled(group.NotifyMentors) {
+		b.tgAPI.Send(tgbotapi.ForwardConfig{
+			FromChannelUsername: message.From.UserName,
+			FromChatID:          channel,
+			MessageID:           message.MessageID,
+			BaseChat:            tgbotapi.BaseChat{ChatID: group.MentorsChat},
+		})
+	}
+}
 
-	if update.EditedMessage != nil {
-		channel = update.EditedMessage.Chat.ID
-
-		if !strings.Contains(text, "@"+b.tgAPI.Self.UserName) {
-			logrus.Info("MSG does not mention botuser\n")
-			return
+// handleEdit updates standup when intern edits its message, or accepts the
+// message when the edit makes it a standup
+func (b *Bot) handleEdit(message *tgbotapi.Message) {
+	if message.Chat == nil || message.From == nil || !strings.Contains(standupText(message), "@"+b.self.UserName) {
+		return
+	}
+	channel := message.Chat.ID
+	saved, err := b.db.FindStandupByMessage(channel, message.MessageID)
+	if err == sql.ErrNoRows {
+		if b.isStandup(message) {
+			b.acceptStandup(message)
 		}
+		return
+	}
+	if err != nil {
+		logrus.Errorf("FindStandupByMessage failed: %v\n", err)
+		return
+	}
+	if !b.isStandup(message) {
+		logrus.Infof("This is not a proper edit for standup: %s\n", standupText(message))
+		b.explainRejected(message)
+		return
+	}
+	logrus.Infof("accepted edited standup from %s\n", message.From.UserName)
+	edited := b.parseStandup(message)
+	edited.ID = saved.ID
+	if _, err := b.db.UpdateStandup(edited); err != nil {
+		logrus.Errorf("UpdateStandup failed: %v\n", err)
+		return
+	}
+	b.tgAPI.Send(tgbotapi.NewMessage(channel, b.t(channel, msgStandupEdited, i18n.Vars{"user": message.From.UserName})))
+}
 
-		if !b.isStandup(update.EditedMessage) {
-			logrus.Infof("This is not a proper edit for standup: %s\n", update.EditedMessage.Text)
-			return
+// trackInterns remembers Telegram user IDs of interns who write to the group
+// or join it, and keeps their usernames fresh
+func (b *Bot) trackInterns(message *tgbotapi.Message) {
+	if message.Chat == nil {
+		return
+	}
+	users := []*tgbotapi.User{message.From}
+	if message.NewChatMembers != nil {
+		for i := range *message.NewChatMembers {
+			users = append(users, &(*message.NewChatMembers)[i])
 		}
-		logrus.Infof("accepted edited standup from %s\n", update.EditedMessage.From.UserName)
-		standup := model.Standup{
-			Comment:  update.EditedMessage.Text,
-			Username: update.EditedMessage.From.UserName,
+	}
+	for _, user := range users {
+		if user == nil || user.IsBot {
+			continue
+		}
+		intern, err := b.db.FindInternByUserID(int64(user.ID), message.Chat.ID)
+		if err == sql.ErrNoRows && user.UserName != "" {
+			intern, err = b.db.FindIntern(user.UserName, message.Chat.ID)
+			if err == nil && intern.UserID != 0 {
+				// username was taken over by someone else
+				continue
+			}
 		}
-		_, err := b.db.UpdateStandup(standup)
 		if err != nil {
-			logrus.Errorf("UpdateStandup failed: %v\n", err)
-			return
+			if err != sql.ErrNoRows {
+				logrus.Errorf("trackInterns failed: %v\n", err)
+			}
+			continue
+		}
+		if intern.UserID == int64(user.ID) && (user.UserName == "" || intern.Username == user.UserName) {
+			continue
+		}
+		intern.UserID = int64(user.ID)
+		if user.UserName != "" {
+			intern.Username = user.UserName
+		}
+		logrus.Infof("Intern [%v] is Telegram user [%v]\n", intern.Username, intern.UserID)
+		if _, err := b.db.UpdateIntern(intern); err != nil {
+			logrus.Errorf("UpdateIntern failed: %v\n", err)
 		}
-		b.tgAPI.Send(tgbotapi.NewMessage(channel, fmt.Sprintf("@%s спасибо. исправления приняты.", update.Message.From.UserName)))
 	}
 }
 
-func (b *Bot) dailyJob() {
-	if _, err := b.checkStandups(); err != nil {
-		logrus.Errorf("checkStandups failed: %v\n", err)
+func (b *Bot) dailyJob(group model.Group) {
+	start := time.Now()
+	err := b.checkGroupStandups(group)
+	metrics.DailyJobDuration.Observe(time.Since(start).Seconds())
+	if err == nil || err == errDayOff {
+		metrics.DailyJobLastSuccess.WithLabelValues(metrics.Group(group.ID)).Set(float64(b.now().UnixNano()) / 1e9)
+	}
+	if err == errDayOff {
+		logrus.Infof("Day off, skip checking group [%v]\n", group.ID)
+		return
+	}
+	if err != nil {
+		logrus.Errorf("checkGroupStandups failed: %v\n", err)
 	}
 }
 
-func (b *Bot) senderIsAdminInChannel(sendername string, chatID int64) (bool, error) {
+// senderIsAdminInChannel compares Telegram IDs, usernames are optional and
+// users without one would match each other
+func (b *Bot) senderIsAdminInChannel(sender *tgbotapi.User, chatID int64) (bool, error) {
 	isAdmin := false
-	chat := tgbotapi.ChatConfig{chatID, ""}
+	chat := tgbotapi.ChatConfig{ChatID: chatID}
 	admins, err := b.tgAPI.GetChatAdministrators(chat)
 	if err != nil {
 		return false, err
 	}
 	for _, admin := range admins {
-		if admin.User.UserName == sendername {
+		if admin.User != nil && admin.User.ID == sender.ID {
 			isAdmin = true
 			return true, nil
 		}
@@ -203,185 +400,352 @@ func (b *Bot) senderIsAdminInChannel(sendername string, chatID int64) (bool, err
 	return isAdmin, nil
 }
 
-func (b *Bot) checkStandups() (string, error) {
-	logrus.Info("Start checkStandups")
-	if time.Now().Weekday().String() == "Saturday" || time.Now().Weekday().String() == "Sunday" {
-		return "", errors.New("day off")
+// sameDay reports whether a and b fall on the same date in loc
+func sameDay(a, b time.Time, loc *time.Location) bool {
+	y1, m1, d1 := a.In(loc).Date()
+	y2, m2, d2 := b.In(loc).Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
+
+// submittedToday reports whether intern has sent a standup on the date of now
+// in now's location
+func (b *Bot) submittedToday(intern model.Intern, now time.Time) (bool, error) {
+	last, err := b.lastStandup(intern)
+	if err == sql.ErrNoRows {
+		return false, nil
 	}
-	interns, err := b.db.ListInterns()
 	if err != nil {
-		return "", err
+		return false, err
 	}
-	for _, intern := range interns {
-		standup, err := b.db.LastStandupFor(intern.Username, intern.GroupID)
+	return sameDay(now, last.Created, now.Location()), nil
+}
+
+// lastStandup returns the last standup intern posted to the group, or to any
+// group the intern is enrolled in when the group shares standups
+func (b *Bot) lastStandup(intern model.Intern) (model.Standup, error) {
+	if !enabled(b.groupSettings(intern.GroupID).SharedStandups) {
+		return b.db.LastStandupFor(intern.Username, intern.GroupID)
+	}
+	interns, err := b.db.FindInternsByUsername(intern.Username)
+	if err != nil {
+		return model.Standup{}, err
+	}
+	last := model.Standup{}
+	for _, i := range interns {
+		s, err := b.db.LastStandupFor(i.Username, i.GroupID)
+		if err == sql.ErrNoRows {
+			continue
+		}
 		if err != nil {
-			logrus.Info("Intern does not have any standups! Punish")
-			if err == sql.ErrNoRows {
-				b.Punish(intern)
-				continue
-			} else {
-				return "", err
-			}
+			return model.Standup{}, err
 		}
-		t, _ := time.LoadLocation("Asia/Bishkek")
-		if time.Now().Day() != standup.Created.In(t).Day() {
-			logrus.Infof("Today is %v; last standup created at [%v]", time.Now().Day(), standup.Created.In(t).Day())
-			logrus.Info("Intern did not submit standup today! Punish!")
-			b.Punish(intern)
+		if s.Created.After(last.Created) {
+			last = s
 		}
 	}
+	if last.ID == 0 {
+		return last, sql.ErrNoRows
+	}
+	return last, nil
+}
+
+// checkStandups checks all groups at once regardless of their deadlines.
+// Groups in other timezones may still have a day off when it is a workday
+// in the default timezone, they are skipped.
+func (b *Bot) checkStandups() (string, error) {
+	logrus.Info("Start checkStandups")
+	if _, off := b.dayOff(model.Group{Workdays: b.c.Workdays}, b.now().In(b.loc)); off {
+		return "", errDayOff
+	}
 	groups, err := b.db.ListGroups()
 	if err != nil {
 		return "", err
 	}
 	for _, group := range groups {
-		message := tgbotapi.NewMessage(group, "Каратель завершил свою работу ;)")
-		b.tgAPI.Send(message)
+		err := b.checkGroupStandups(b.groupSettings(group))
+		if err != nil && err != errDayOff {
+			return "", err
+		}
 	}
 
-	return "Каратель завершил свою работу ;)", nil
+	// no group has ID 0, so it is worded in the default locale
+	return b.t(0, msgCheckDone, nil), nil
+}
+
+// checkGroupStandups punishes interns of the group who did not submit standup today
+func (b *Bot) checkGroupStandups(group model.Group) error {
+	now := b.now().In(b.location(group))
+	if reason, off := b.dayOff(group, now); off {
+		if reason != "" {
+			b.tgAPI.Send(tgbotapi.NewMessage(group.ID, b.t(group.ID, msgCheckDayOff, i18n.Vars{"reason": reason})))
+		}
+		return errDayOff
+	}
+	interns, err := b.db.ListGroupInterns(group.ID)
+	if err != nil {
+		return err
+	}
+	today := calendar.Day(now)
+	absences, err := b.absencesFrom(group.ID, today)
+	if err != nil {
+		return err
+	}
+	unfinished, err := b.unfinishedPunishments(group.ID)
+	if err != nil {
+		return err
+	}
+	excused := []string{}
+	for _, intern := range interns {
+		if absence, ok := activeAbsence(absences[intern.ID], today); ok {
+			excused = append(excused, b.formatAbsence(intern, absence))
+			continue
+		}
+		submitted, err := b.submittedToday(intern, now)
+		if err != nil {
+			return err
+		}
+		intern, bonus, err := b.updateStreak(group, intern, submitted)
+		if err != nil {
+			return err
+		}
+		if bonus != "" {
+			b.tgAPI.Send(tgbotapi.NewMessage(group.ID, bonus))
+		}
+		if !submitted {
+			logrus.Infof("Intern %s did not submit standup today! Punish!", intern.Username)
+			b.Punish(intern)
+		}
+	}
+	text := b.t(group.ID, msgCheckDone, nil)
+	if len(excused) > 0 {
+		text += "\n" + b.t(group.ID, msgCheckExcused, nil) + "\n" + strings.Join(excused, "\n")
+	}
+	if len(unfinished) > 0 {
+		text += "\n" + b.t(group.ID, msgCheckUnfinished, nil) + "\n" + strings.Join(unfinished, "\n")
+	}
+	b.tgAPI.Send(tgbotapi.NewMessage(group.ID, text))
+	return nil
 }
 
 func (b *Bot) isStandup(message *tgbotapi.Message) bool {
 	logrus.Info("checking message...\n")
-	var mentionsProblem, mentionsYesterdayWork, mentionsTodayPlans bool
+	return b.parser(message).IsStandup(standupText(message))
+}
 
-	problemKeys := []string{"роблем", "рудност", "атруднен", "блок"}
-	for _, problem := range problemKeys {
-		if strings.Contains(message.Text, problem) {
-			mentionsProblem = true
-		}
+// standupText returns text of the message, or its caption when intern posts
+// a screenshot with the standup
+func standupText(message *tgbotapi.Message) string {
+	if message.Text != "" {
+		return message.Text
 	}
+	return message.Caption
+}
 
-	yesterdayWorkKeys := []string{"чера", "ятницу", "делал", "делано"}
-	for _, work := range yesterdayWorkKeys {
-		if strings.Contains(message.Text, work) {
-			mentionsYesterdayWork = true
-		}
+// attachments returns files posted with the message, StandupID is left for
+// the caller to fill in
+func attachments(message *tgbotapi.Message) []model.Attachment {
+	files := []model.Attachment{}
+	if message.Photo != nil && len(*message.Photo) > 0 {
+		// Telegram lists sizes of a photo from the smallest, keep the original
+		sizes := *message.Photo
+		files = append(files, model.Attachment{Type: model.AttachmentPhoto, FileID: sizes[len(sizes)-1].FileID})
+	}
+	if message.Video != nil {
+		files = append(files, model.Attachment{Type: model.AttachmentVideo, FileID: message.Video.FileID})
 	}
+	if message.Document != nil {
+		files = append(files, model.Attachment{Type: model.AttachmentDocument, FileID: message.Document.FileID})
+	}
+	return files
+}
 
-	todayPlansKeys := []string{"егодн", "обираюс", "ланир"}
-	for _, plan := range todayPlansKeys {
-		if strings.Contains(message.Text, plan) {
-			mentionsTodayPlans = true
-		}
+// parser returns standup parser for languages of the message's group
+func (b *Bot) parser(message *tgbotapi.Message) standup.Parser {
+	codes := b.c.Languages
+	if message.Chat != nil {
+		codes = b.groupSettings(message.Chat.ID).Languages
 	}
-	return mentionsProblem && mentionsYesterdayWork && mentionsTodayPlans
+	return b.languages.Parser(codes)
 }
 
-//RemoveLives removes live from intern
+// sectionMessages name standup sections in explanations
+var sectionMessages = map[standup.Section]string{
+	standup.Yesterday: msgSectionYesterday,
+	standup.Today:     msgSectionToday,
+	standup.Blockers:  msgSectionBlockers,
+}
+
+// explainRejected tells intern which sections the message lacks to be a standup
+func (b *Bot) explainRejected(message *tgbotapi.Message) {
+	channel := message.Chat.ID
+	sections := b.parser(message).Missing(standupText(message))
+	metrics.StandupsRejected.WithLabelValues(rejectReason(sections)).Inc()
+	missing := []string{}
+	for _, s := range sections {
+		missing = append(missing, b.t(channel, sectionMessages[s], nil))
+	}
+	b.tgAPI.Send(tgbotapi.NewMessage(channel, b.t(channel, msgStandupMissing, i18n.Vars{
+		"user":    message.From.UserName,
+		"bot":     b.self.UserName,
+		"missing": strings.Join(missing, ", "),
+	})))
+}
+
+// parseStandup makes standup entry of message split into sections
+func (b *Bot) parseStandup(message *tgbotapi.Message) model.Standup {
+	text := standupText(message)
+	sections := b.parser(message).Parse(text)
+	return model.Standup{
+		Comment:   text,
+		Username:  message.From.UserName,
+		Yesterday: sections.Yesterday,
+		Today:     sections.Today,
+		Blockers:  sections.Blockers,
+		GroupID:   message.Chat.ID,
+		ChatID:    message.Chat.ID,
+		MessageID: message.MessageID,
+	}
+}
+
+// RemoveLives removes live from intern
 func (b *Bot) RemoveLives(intern model.Intern) (string, error) {
+	text, err := b.takeLife(intern)
+	if err != nil {
+		return "", err
+	}
+	b.tgAPI.Send(tgbotapi.NewMessage(intern.GroupID, text))
+	return text, nil
+}
+
+// takeLife removes live from intern kicking the one who has no lives left
+func (b *Bot) takeLife(intern model.Intern) (string, error) {
 	intern.Lives--
 	_, err := b.db.UpdateIntern(intern)
 	if err != nil {
 		return "", err
 	}
-	message := tgbotapi.NewMessage(intern.GroupID, fmt.Sprintf("@%s осталось жизней: %d", intern.Username, intern.Lives))
-	if intern.Lives == 0 {
+	if intern.Lives > 0 {
+		return b.t(intern.GroupID, msgLivesLeft, i18n.Vars{"user": intern.Username, "lives": intern.Lives}), nil
+	}
+	if intern.UserID == 0 {
+		// intern was added by @username and never wrote to the group
+		logrus.Warnf("Telegram ID of %s is unknown, can not kick\n", intern.Username)
+	} else {
 		chatMemberConf := tgbotapi.ChatMemberConfig{
 			ChatID: intern.GroupID,
-			UserID: int(intern.ID),
+			UserID: int(intern.UserID),
 		}
-		conf := tgbotapi.KickChatMemberConfig{chatMemberConf, 0}
-		r, err := b.tgAPI.KickChatMember(conf)
-		if err != nil {
-			fmt.Println(err)
+		conf := tgbotapi.KickChatMemberConfig{ChatMemberConfig: chatMemberConf}
+		if _, err := b.tgAPI.KickChatMember(conf); err != nil {
+			logrus.Errorf("KickChatMember failed: %v\n", err)
 		}
-		fmt.Println(r)
-		message = tgbotapi.NewMessage(intern.GroupID, fmt.Sprintf("У @%s не осталось жизней. Удаляю.", intern.Username))
 	}
-	b.tgAPI.Send(message)
-	return message.Text, nil
+	return b.t(intern.GroupID, msgLivesKicked, i18n.Vars{"user": intern.Username}), nil
 }
 
-//PunishByPushUps tells interns to do random # of pushups
+// PunishByPushUps tells interns to do random # of pushups
 func (b *Bot) PunishByPushUps(intern model.Intern, min, max int) (int, string, error) {
-	rand.Seed(time.Now().Unix())
-	pushUps := rand.Intn(max-min) + min
-	message := tgbotapi.NewMessage(intern.GroupID, fmt.Sprintf("@%s в наказание за пропущенный стэндап тебе %d отжиманий", intern.Username, pushUps))
-	b.tgAPI.Send(message)
-	return pushUps, message.Text, nil
+	return b.punishByExercise(Exercise{ID: pushUps.ID, Min: min, Max: max}, intern)
 }
 
-//PunishByMakingSnowFlakes tells interns to do random # of pushups
+// PunishByMakingSnowFlakes tells interns to make random # of snowflakes
 func (b *Bot) PunishByMakingSnowFlakes(intern model.Intern, min, max int) (int, string, error) {
-	rand.Seed(time.Now().Unix())
-	snowFlakes := rand.Intn(max-min) + min
-	message := tgbotapi.NewMessage(intern.GroupID, fmt.Sprintf("@%s, в наказание за пропущенный стэндап c тебя %d снежинок!", intern.Username, snowFlakes))
-	b.tgAPI.Send(message)
-	return snowFlakes, message.Text, nil
+	return b.punishByExercise(Exercise{ID: snowFlakes.ID, Min: min, Max: max}, intern)
 }
 
-//PunishBySitUps tells interns to do random # of pushups
+// PunishBySitUps tells interns to do random # of situps
 func (b *Bot) PunishBySitUps(intern model.Intern, min, max int) (int, string, error) {
-	rand.Seed(time.Now().Unix())
-	situps := rand.Intn(max-min) + min
-	message := tgbotapi.NewMessage(intern.GroupID, fmt.Sprintf("@%s в наказание за пропущенный стэндап тебе %d приседаний", intern.Username, situps))
+	return b.punishByExercise(Exercise{ID: sitUps.ID, Min: min, Max: max}, intern)
+}
+
+func (b *Bot) punishByExercise(e Exercise, intern model.Intern) (int, string, error) {
+	n := e.roll()
+	message := tgbotapi.NewMessage(intern.GroupID, e.localize(b, intern, n))
 	b.tgAPI.Send(message)
-	return situps, message.Text, nil
+	return n, message.Text, nil
 }
 
-//PunishByPoetry tells interns to read random poetry
+// PunishByPoetry tells interns to read random poetry
 func (b *Bot) PunishByPoetry(intern model.Intern, link string) (string, string, error) {
-	message := tgbotapi.NewMessage(intern.GroupID, fmt.Sprintf("@%s в наказание за пропущенный стэндап прочитай этот стих на весь офис: %v", intern.Username, link))
+	message := tgbotapi.NewMessage(intern.GroupID, b.t(intern.GroupID, msgPunishmentPoetry, i18n.Vars{"user": intern.Username, "link": link}))
 	b.tgAPI.Send(message)
 	return link, message.Text, nil
 }
 
-//Punish punishes interns by either removing lives or asking them to do push ups
+// Punish punishes intern the way the group has chosen. Groups that chose
+// "random" or a punishment that is no longer registered get a random one.
 func (b *Bot) Punish(intern model.Intern) {
-	switch punishment := b.c.PunishmentType; punishment {
-	case "pushups":
-		b.PunishByPushUps(intern, 5, 100)
-	case "snowflakes":
-		b.PunishByMakingSnowFlakes(intern, 10, 150)
-	case "removelives":
-		b.RemoveLives(intern)
-	case "situps":
-		b.PunishBySitUps(intern, 20, 200)
-	case "poetry":
-		link := generatePoetryLink()
-		b.PunishByPoetry(intern, link)
-	case "random":
-		b.randomPunishment(intern)
-	default:
-		b.randomPunishment(intern)
-	}
-}
-
-func (b *Bot) randomPunishment(intern model.Intern) {
-	rand.Seed(time.Now().Unix())
-	switch r := rand.Intn(4); r {
-	case 0:
-		b.PunishByMakingSnowFlakes(intern, 10, 150)
-	case 1:
-		b.RemoveLives(intern)
-	case 2:
-		b.PunishBySitUps(intern, 20, 200)
-	case 3:
-		link := generatePoetryLink()
-		b.PunishByPoetry(intern, link)
-	case 4:
-		b.PunishByPushUps(intern, 5, 100)
+	p, ok := b.punishments.Get(b.groupSettings(intern.GroupID).PunishmentType)
+	if !ok {
+		if p, ok = b.punishments.Random(); !ok {
+			logrus.Errorf("No punishment to pick for %s\n", intern.Username)
+			return
+		}
+	}
+	amount, text, err := p.Apply(intern)
+	if err != nil {
+		logrus.Errorf("%s punishment failed: %v\n", p.Name(), err)
+		return
+	}
+	metrics.PunishmentsIssued.WithLabelValues(p.Name()).Inc()
+	if l, ok := p.(localized); ok {
+		text = l.localize(b, intern, amount)
+	}
+	message, err := b.tgAPI.Send(tgbotapi.NewMessage(intern.GroupID, text))
+	if err != nil {
+		logrus.Errorf("Send failed: %v\n", err)
+	}
+	record := model.Punishment{
+		InternID:  intern.ID,
+		GroupID:   intern.GroupID,
+		Type:      p.Name(),
+		Amount:    amount,
+		Issued:    b.now().UTC(),
+		Status:    model.PunishmentPending,
+		MessageID: message.MessageID,
+	}
+	if _, ok := p.(immediate); ok {
+		record.Status = model.PunishmentDone
+	}
+	if _, err := b.db.CreatePunishment(record); err != nil {
+		logrus.Errorf("CreatePunishment failed: %v\n", err)
 	}
 }
 
+// poetryRand picks poems, it has its own source like punishmentRand
+var poetryRand = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// poetryAttempts limits how many random links are checked before giving up
+// on finding an existing poem
+const poetryAttempts = 10
+
+// poetryClient checks poems, it must not hang the scheduler on a slow site
+var poetryClient = &http.Client{Timeout: 10 * time.Second}
+
 func generatePoetryLink() string {
-	rand.Seed(time.Now().Unix())
-	year := rand.Intn(2018-2008) + 2008
-	month := rand.Intn(12-1) + 1
-	date := rand.Intn(30-1) + 1
-	id := rand.Intn(4100-10) + 10
+	return generatePoetryLinkAttempts(poetryAttempts)
+}
+
+func generatePoetryLinkAttempts(attempts int) string {
+	poetryRand.Lock()
+	year := poetryRand.Intn(2018-2008) + 2008
+	month := poetryRand.Intn(12-1) + 1
+	date := poetryRand.Intn(30-1) + 1
+	id := poetryRand.Intn(4100-10) + 10
+	poetryRand.Unlock()
 	link := fmt.Sprintf("https://www.stihi.ru/%04d/%02d/%02d/%v", year, month, date, id)
-	if !poetryExist(link) {
-		generatePoetryLink()
+	if attempts > 1 && !poetryExist(link) {
+		return generatePoetryLinkAttempts(attempts - 1)
 	}
 	return link
 }
 
-//poetryExist checks if link leads to real poetry and not 404 error
+// poetryExist checks if link leads to real poetry and not 404 error
 func poetryExist(link string) bool {
-	resp, err := http.Get(link)
+	resp, err := poetryClient.Get(link)
 	if err != nil {
 		fmt.Println(err)
 		return false
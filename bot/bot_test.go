@@ -3,36 +3,42 @@ package bot
 import (
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
-	"github.com/bouk/monkey"
-	"github.com/jarcoal/httpmock"
+	"github.com/maddevsio/punisher/calendar"
 	"github.com/maddevsio/punisher/config"
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/metrics"
 	"github.com/maddevsio/punisher/model"
+	"github.com/maddevsio/punisher/standup"
+	"github.com/maddevsio/punisher/storage"
+	"github.com/maddevsio/punisher/telegramtest"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/telegram-bot-api.v4"
 )
 
 const BotToken = "testToken"
 const BotChat = "-12345"
-const BotDatabaseURL = "root:root@/interns?parseTime=true"
+const BotDatabaseURL = "memory://"
 
 func TestCheckStandups(t *testing.T) {
-	b := setupTestBot(t)
-	b.dailyJob()
-	d := time.Date(2018, time.April, 1, 1, 2, 3, 4, time.UTC)
-	monkey.Patch(time.Now, func() time.Time { return d })
+	clock := newTestClock(time.Now())
+	b, _ := setupTestBot(t, WithClock(clock.Now))
+	b.dailyJob(b.groupSettings(0))
+	clock.Set(time.Date(2018, time.April, 1, 1, 2, 3, 4, time.UTC))
 	_, err := b.checkStandups()
 	assert.Equal(t, errors.New("day off").Error(), err.Error())
-	d = time.Date(2018, time.April, 7, 1, 2, 3, 4, time.UTC)
-	monkey.Patch(time.Now, func() time.Time { return d })
+	clock.Set(time.Date(2018, time.April, 7, 1, 2, 3, 4, time.UTC))
 	_, err = b.checkStandups()
 	assert.Equal(t, errors.New("day off").Error(), err.Error())
 
-	d = time.Date(2018, time.April, 2, 11, 2, 3, 4, time.UTC)
-	monkey.Patch(time.Now, func() time.Time { return d })
+	clock.Set(time.Date(2018, time.April, 2, 11, 2, 3, 4, time.UTC))
 	intern, err := b.db.CreateIntern(model.Intern{
 		Username: "testUser1",
 		Lives:    3,
@@ -49,11 +55,10 @@ func TestCheckStandups(t *testing.T) {
 		Comment:  "first standup",
 	})
 	assert.NoError(t, err)
-	b.dailyJob()
+	b.dailyJob(b.groupSettings(0))
 
-	d = time.Date(2018, time.April, 4, 11, 2, 3, 4, time.UTC)
-	monkey.Patch(time.Now, func() time.Time { return d })
-	b.dailyJob()
+	clock.Set(time.Date(2018, time.April, 4, 11, 2, 3, 4, time.UTC))
+	b.dailyJob(b.groupSettings(0))
 
 	assert.NoError(t, b.db.DeleteIntern(intern.ID))
 	assert.NoError(t, b.db.DeleteStandup(s.ID))
@@ -61,7 +66,7 @@ func TestCheckStandups(t *testing.T) {
 }
 
 func TestIsStandup(t *testing.T) {
-	b := setupTestBot(t)
+	b, _ := setupTestBot(t)
 
 	var testCases = []struct {
 		message string
@@ -81,7 +86,7 @@ func TestIsStandup(t *testing.T) {
 
 func TestHandleUpdate(t *testing.T) {
 
-	b := setupTestBot(t)
+	b, _ := setupTestBot(t)
 	b.handleUpdate(tgbotapi.Update{
 		Message: &tgbotapi.Message{},
 	})
@@ -146,47 +151,373 @@ func TestHandleUpdate(t *testing.T) {
 
 }
 
+func TestConversation(t *testing.T) {
+	b, srv, chat, say := setupTestGroup(t)
+
+	say("mentor", "@testbot_bot добавь @intern")
+	say("mentor", "@testbot_bot добавь @intern")
+	say("intern", "@testbot_bot Вчера делал бота. Сегодня планирую тесты. Проблем нет")
+	say("mentor", "@testbot_bot удали @intern")
+
+	assert.Equal(t, []string{
+		"@intern, я слежу за тобой.",
+		"Уже слежу за @intern, зачем 2 раза просить?",
+		"@intern спасибо. Я принял твой стендап",
+		"@intern, я больше не слежу за тобой.",
+	}, srv.Messages(chat.ID))
+
+	interns, err := b.db.ListInterns()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(interns))
+	standups, err := b.db.ListStandups()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(standups))
+}
+
+func TestTrackInterns(t *testing.T) {
+	b, srv, chat, _ := setupTestGroup(t)
+	intern, err := b.db.CreateIntern(model.Intern{Username: "intern", Lives: 1, GroupID: chat.ID})
+	assert.NoError(t, err)
+
+	b.handleUpdate(tgbotapi.Update{Message: &tgbotapi.Message{
+		Chat:           chat,
+		From:           testUser("mentor"),
+		NewChatMembers: &[]tgbotapi.User{{ID: 77, UserName: "intern"}},
+	}})
+	intern, err = b.db.SelectIntern(intern.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(77), intern.UserID)
+
+	// username change is picked up by Telegram ID
+	b.handleUpdate(tgbotapi.Update{Message: &tgbotapi.Message{
+		Chat: chat,
+		From: &tgbotapi.User{ID: 77, UserName: "renamed"},
+		Text: "hello",
+	}})
+	intern, err = b.db.SelectIntern(intern.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "renamed", intern.Username)
+
+	text, err := b.RemoveLives(intern)
+	assert.NoError(t, err)
+	assert.Equal(t, "У @renamed не осталось жизней. Удаляю.", text)
+	kicks := srv.Requests("kickChatMember")
+	assert.Equal(t, 1, len(kicks))
+	assert.Equal(t, "77", kicks[0].Params.Get("user_id"))
+}
+
+func TestGroupSettings(t *testing.T) {
+	bishkek, _ := time.LoadLocation("Asia/Bishkek")
+	clock := newTestClock(time.Date(2018, time.April, 2, 10, 0, 3, 4, bishkek))
+	b, srv, chat, say := setupTestGroup(t, WithClock(clock.Now))
+
+	say("intern", "@testbot_bot настройки жизни 1")
+	say("mentor", "@testbot_bot настройки наказание казнь")
+	say("mentor", "@testbot_bot настройки время 9:30")
+	say("mentor", "@testbot_bot настройки наказание removelives")
+	say("mentor", "@testbot_bot настройки жизни 5")
+	messages := srv.Messages(chat.ID)
+	assert.Equal(t, 5, len(messages))
+	assert.Equal(t, "Менять настройки могут только админы группы", messages[0])
+	assert.Contains(t, messages[1], "не знаю такого наказания")
+	assert.Equal(t, "Настройки группы:\nвремя: 09:30\nпояс: Asia/Bishkek\nдни: пн,вт,ср,чт,пт\nнапоминать: 60,15\nлично: нет\nнаказание: removelives\nуведомлять: нет\nменторы: 0\nжизни: 5\nбонус: 5\nмаксимум: 5\nязыки: ru\nлокаль: ru\nобщий: нет", messages[4])
+
+	group := b.groupSettings(chat.ID)
+	assert.Equal(t, "09:30", group.PunishTime)
+	assert.Equal(t, 5, group.Lives)
+	// other groups keep defaults from config
+	assert.Equal(t, "10:00", b.groupSettings(-200).PunishTime)
+	// only changed settings are stored, the rest follow config
+	stored, err := b.db.SelectGroup(chat.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Group{ID: chat.ID, PunishTime: "09:30", PunishmentType: "removelives", Lives: 5}, stored)
+	b.c.RemindPrivately = true
+	b.c.Lives = 2
+	group = b.groupSettings(chat.ID)
+	assert.True(t, enabled(group.RemindPrivately))
+	assert.Equal(t, 5, group.Lives)
+	b.c.RemindPrivately = false
+	b.c.Lives = 3
+
+	say("mentor", "@testbot_bot добавь @intern")
+	intern, err := b.db.FindIntern("intern", chat.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, intern.Lives)
+
+	// deadline of the group is 09:30, so 10:00 check is not started
+	b.scheduleChecks()
+	intern, _ = b.db.FindIntern("intern", chat.ID)
+	assert.Equal(t, 5, intern.Lives)
+
+	clock.Set(time.Date(2018, time.April, 2, 9, 30, 3, 4, bishkek))
+	b.scheduleChecks()
+	b.scheduleChecks()
+	intern, _ = b.db.FindIntern("intern", chat.ID)
+	assert.Equal(t, 4, intern.Lives)
+}
+
+func TestScheduleSkippedMinute(t *testing.T) {
+	bishkek, _ := time.LoadLocation("Asia/Bishkek")
+	clock := newTestClock(time.Date(2026, time.October, 19, 9, 59, 30, 0, bishkek))
+	b, _ := setupTestBot(t, WithClock(clock.Now))
+	group := model.Group{ID: -100, PunishTime: "10:00", Timezone: "Asia/Bishkek", PunishmentType: "pushups"}
+	_, err := b.db.CreateGroup(group)
+	assert.NoError(t, err)
+	_, err = b.db.CreateIntern(model.Intern{Username: "intern", GroupID: group.ID, Lives: 3})
+	assert.NoError(t, err)
+	b.scheduleChecks()
+	// the tick of 10:00 was late
+	clock.Set(time.Date(2026, time.October, 19, 10, 1, 10, 0, bishkek))
+	b.scheduleChecks()
+	punishments, _ := b.db.ListPunishments(group.ID)
+	assert.Equal(t, 1, len(punishments))
+
+	// after a restart passed deadlines are not caught up
+	b.prevTick = time.Time{}
+	b.lastCheck = map[int64]string{}
+	clock.Set(time.Date(2026, time.October, 19, 10, 30, 0, 0, bishkek))
+	b.scheduleChecks()
+	punishments, _ = b.db.ListPunishments(group.ID)
+	assert.Equal(t, 1, len(punishments))
+}
+
+func TestGroupTimezone(t *testing.T) {
+	// Monday 23:00 in New York
+	newYork, _ := time.LoadLocation("America/New_York")
+	clock := newTestClock(time.Date(2018, time.April, 2, 23, 0, 0, 0, newYork))
+	b, srv, chat, say := setupTestGroup(t, WithClock(clock.Now))
+	say("mentor", "@testbot_bot настройки пояс Mars/Olympus")
+	say("mentor", "@testbot_bot настройки пояс America/New_York")
+	messages := srv.Messages(chat.ID)
+	assert.Contains(t, messages[0], "не знаю часового пояса Mars/Olympus")
+	assert.Contains(t, messages[1], "пояс: America/New_York")
+
+	say("mentor", "@testbot_bot настройки наказание removelives")
+	say("mentor", "@testbot_bot добавь @intern")
+	// the intern writes a standup
+	_, err := b.db.CreateStandup(model.Standup{Username: "intern", GroupID: chat.ID, Comment: "standup"})
+	assert.NoError(t, err)
+	// it is already Tuesday in UTC and Bishkek, but still Monday in the group
+	clock.Set(time.Date(2018, time.April, 2, 23, 30, 0, 0, newYork))
+	assert.NoError(t, b.checkGroupStandups(b.groupSettings(chat.ID)))
+	intern, _ := b.db.FindIntern("intern", chat.ID)
+	assert.Equal(t, 3, intern.Lives)
+
+	// next morning in New York there is no standup for today
+	clock.Set(time.Date(2018, time.April, 3, 10, 0, 0, 0, newYork))
+	assert.NoError(t, b.checkGroupStandups(b.groupSettings(chat.ID)))
+	intern, _ = b.db.FindIntern("intern", chat.ID)
+	assert.Equal(t, 2, intern.Lives)
+
+	// Saturday in New York while it is Sunday in Bishkek
+	clock.Set(time.Date(2018, time.April, 7, 20, 0, 0, 0, newYork))
+	assert.Equal(t, errDayOff, b.checkGroupStandups(b.groupSettings(chat.ID)))
+}
+
+func TestDaysOff(t *testing.T) {
+	bishkek, _ := time.LoadLocation("Asia/Bishkek")
+	clock := newTestClock(time.Date(2018, time.April, 2, 10, 0, 0, 0, bishkek))
+	b, srv, chat, say := setupTestGroup(t, WithClock(clock.Now))
+	newYear, _ := calendar.ParseDate("2018-01-01")
+	b.holidays = &calendar.Calendar{Holidays: []calendar.Holiday{{Name: "Новый год", From: newYear, To: newYear, Yearly: true}}}
+
+	say("mentor", "@testbot_bot настройки наказание removelives")
+	say("mentor", "@testbot_bot добавь @intern")
+	say("mentor", "@testbot_bot выходной 2018-04-03 субботник")
+	say("mentor", "@testbot_bot выходной 2018-04-04")
+	say("mentor", "@testbot_bot рабочий 2018-04-04")
+	say("mentor", "@testbot_bot настройки дни вт,ср,чт,пт,сб")
+	srv.Reset()
+	say("mentor", "@testbot_bot выходные")
+	assert.Equal(t, []string{"Выходные:\n2018-04-03 — субботник"}, srv.Messages(chat.ID))
+
+	var testCases = []struct {
+		day   time.Time
+		off   bool
+		lives int
+	}{
+		{time.Date(2018, time.April, 2, 10, 0, 0, 0, bishkek), true, 3},   // monday is not a workday now
+		{time.Date(2018, time.April, 3, 10, 0, 0, 0, bishkek), true, 3},   // субботник
+		{time.Date(2018, time.April, 4, 10, 0, 0, 0, bishkek), false, 2},  // day off was cancelled
+		{time.Date(2018, time.April, 7, 10, 0, 0, 0, bishkek), false, 1},  // saturday is a workday
+		{time.Date(2019, time.January, 1, 10, 0, 0, 0, bishkek), true, 1}, // holiday from calendar
+	}
+	for _, tt := range testCases {
+		clock.Set(tt.day)
+		srv.Reset()
+		err := b.checkGroupStandups(b.groupSettings(chat.ID))
+		assert.Equal(t, tt.off, err == errDayOff, tt.day.String())
+		intern, _ := b.db.FindIntern("intern", chat.ID)
+		assert.Equal(t, tt.lives, intern.Lives, tt.day.String())
+	}
+	assert.Equal(t, []string{"Сегодня выходной (Новый год), никого не наказываю. Отдыхайте!"}, srv.Messages(chat.ID))
+}
+
+func TestAbsences(t *testing.T) {
+	bishkek, _ := time.LoadLocation("Asia/Bishkek")
+	clock := newTestClock(time.Date(2026, time.October, 19, 10, 0, 0, 0, bishkek))
+	b, srv, chat, say := setupTestGroup(t, WithClock(clock.Now))
+	say("mentor", "@testbot_bot настройки наказание removelives")
+	say("mentor", "@testbot_bot добавь @vasya")
+	say("mentor", "@testbot_bot добавь @petya")
+	say("mentor", "@testbot_bot добавь @masha")
+	srv.Reset()
+	say("mentor", "@testbot_bot отпуск @vasya 2026-10-20 2026-10-27")
+	say("mentor", "@testbot_bot болеет @petya")
+	say("mentor", "@testbot_bot болеет @nobody")
+	say("mentor", "@testbot_bot отпуск @masha 2026-10-27 2026-10-20")
+	assert.Equal(t, []string{
+		"Ок, @vasya в отпуске с 2026-10-20 по 2026-10-27, не наказываю",
+		"Ок, @petya болеет 2026-10-19, не наказываю",
+		"я и не слежу за @nobody",
+		"Отсутствие не может закончиться раньше, чем началось",
+	}, srv.Messages(chat.ID))
+
+	srv.Reset()
+	say("mentor", "@testbot_bot выходные")
+	assert.Equal(t, []string{"Ближайший месяц без праздников. Рабочие дни: пн,вт,ср,чт,пт\n\n" +
+		"Отсутствуют:\n@vasya в отпуске с 2026-10-20 по 2026-10-27\n@petya болеет 2026-10-19"}, srv.Messages(chat.ID))
+
+	lives := func() []int {
+		l := []int{}
+		for _, name := range []string{"vasya", "petya", "masha"} {
+			intern, _ := b.db.FindIntern(name, chat.ID)
+			l = append(l, intern.Lives)
+		}
+		return l
+	}
+
+	srv.Reset()
+	assert.NoError(t, b.checkGroupStandups(b.groupSettings(chat.ID)))
+	assert.Equal(t, []int{2, 3, 2}, lives())
+	assert.Contains(t, srv.Messages(chat.ID), "Каратель завершил свою работу ;)\nНе проверял:\n@petya болеет 2026-10-19")
+
+	clock.Add(48 * time.Hour)
+	assert.NoError(t, b.checkGroupStandups(b.groupSettings(chat.ID)))
+	assert.Equal(t, []int{2, 2, 1}, lives())
+
+	srv.Reset()
+	say("mentor", "@testbot_bot вернулся @vasya")
+	say("mentor", "@testbot_bot вернулся @petya")
+	assert.Equal(t, []string{"С возвращением, @vasya! Жду стендап.", "@petya никуда и не уходил"}, srv.Messages(chat.ID))
+	clock.Add(24 * time.Hour)
+	assert.NoError(t, b.checkGroupStandups(b.groupSettings(chat.ID)))
+	assert.Equal(t, []int{1, 1, 0}, lives())
+}
+
+func TestReminders(t *testing.T) {
+	bishkek, _ := time.LoadLocation("Asia/Bishkek")
+	clock := newTestClock(time.Date(2026, time.October, 19, 8, 30, 0, 0, bishkek))
+	b, srv, chat, say := setupTestGroup(t, WithClock(clock.Now))
+	say("mentor", "@testbot_bot настройки время 10:00")
+	say("mentor", "@testbot_bot настройки напоминать 15, 90,15")
+	say("mentor", "@testbot_bot настройки лично да")
+	say("mentor", "@testbot_bot настройки напоминать никогда")
+	assert.Equal(t, "напоминания нужно указать в минутах до дедлайна через запятую, например 60,15, или нет", srv.Messages(chat.ID)[3])
+	say("mentor", "@testbot_bot добавь @vasya")
+	say("mentor", "@testbot_bot добавь @petya")
+	say("mentor", "@testbot_bot добавь @masha")
+	say("mentor", "@testbot_bot болеет @masha")
+	petya, _ := b.db.FindIntern("petya", chat.ID)
+	petya.UserID = 42
+	b.db.UpdateIntern(petya)
+	b.db.CreateStandup(model.Standup{Username: "vasya", GroupID: chat.ID, Comment: "standup"})
+
+	srv.Reset()
+	b.scheduleChecks()
+	b.scheduleChecks()
+	assert.Equal(t, []string{"@petya, до дедлайна 90 мин., а стендапа от вас еще нет!"}, srv.Messages(chat.ID))
+	assert.Equal(t, []string{"Через 90 мин. дедлайн, не забудь написать стендап в группу!"}, srv.Messages(42))
+
+	srv.Reset()
+	clock.Add(time.Hour)
+	b.scheduleChecks()
+	assert.Equal(t, 0, len(srv.Requests("sendMessage")))
+
+	clock.Add(15 * time.Minute)
+	b.scheduleChecks()
+	assert.Equal(t, []string{"@petya, до дедлайна 15 мин., а стендапа от вас еще нет!"}, srv.Messages(chat.ID))
+
+	// no reminders on weekends
+	srv.Reset()
+	clock.Set(time.Date(2026, time.October, 24, 9, 45, 0, 0, bishkek))
+	b.scheduleChecks()
+	assert.Equal(t, 0, len(srv.Requests("sendMessage")))
+
+	say("mentor", "@testbot_bot настройки напоминать нет")
+	assert.Equal(t, "нет", b.groupSettings(chat.ID).Reminders)
+	srv.Reset()
+	clock.Set(time.Date(2026, time.October, 26, 9, 45, 0, 0, bishkek))
+	b.scheduleChecks()
+	assert.Equal(t, 0, len(srv.Requests("sendMessage")))
+
+	// Friday 23:45 reminds of the deadline on Saturday, which is a day off,
+	// Sunday 23:45 reminds of Monday's deadline
+	say("mentor", "@testbot_bot настройки время 00:15")
+	say("mentor", "@testbot_bot настройки напоминать 30")
+	tick := func(day time.Month, date int) {
+		clock.Set(time.Date(2026, day, date, 23, 44, 30, 0, bishkek))
+		b.scheduleChecks()
+		srv.Reset()
+		clock.Add(time.Minute)
+		b.scheduleChecks()
+	}
+	tick(time.October, 30)
+	assert.Equal(t, 0, len(srv.Requests("sendMessage")))
+	tick(time.November, 1)
+	assert.Equal(t, []string{"@vasya @petya @masha, до дедлайна 30 мин., а стендапа от вас еще нет!"}, srv.Messages(chat.ID))
+}
+
 func TestRemoveLives(t *testing.T) {
-	b := setupTestBot(t)
+	b, _ := setupTestBot(t)
 	intern, err := b.db.CreateIntern(model.Intern{
 		Username: "testUser1",
 		Lives:    3,
 	})
 	text, err := b.RemoveLives(intern)
 	assert.NoError(t, err)
-	assert.Equal(t, "@testUser1 осталось жизней: 2", text)
+	assert.Equal(t, "@testUser1 осталось 2 жизни", text)
 	err = b.db.DeleteIntern(intern.ID)
 	assert.NoError(t, err)
 }
 
 func TestPunishByPushUps(t *testing.T) {
-	b := setupTestBot(t)
+	b, _ := setupTestBot(t)
 	intern, err := b.db.CreateIntern(model.Intern{
 		Username: "testUser1",
 		Lives:    3,
 	})
 	pushUps, text, err := b.PunishByPushUps(intern, 0, 10)
 	assert.NoError(t, err)
-	expected := fmt.Sprintf("@%s в наказание за пропущенный стэндап тебе %d отжиманий", intern.Username, pushUps)
+	expected := fmt.Sprintf("@%s в наказание за пропущенный стэндап тебе %s", intern.Username, i18n.Plural("ru", pushUps, "отжимание", "отжимания", "отжиманий"))
 	assert.Equal(t, expected, text)
 	assert.NoError(t, b.db.DeleteIntern(intern.ID))
 }
 
 func TestPunishBySitUps(t *testing.T) {
-	b := setupTestBot(t)
+	b, _ := setupTestBot(t)
 	intern, err := b.db.CreateIntern(model.Intern{
 		Username: "testUser1",
 		Lives:    3,
 	})
 	pushUps, text, err := b.PunishBySitUps(intern, 0, 10)
 	assert.NoError(t, err)
-	expected := fmt.Sprintf("@%s в наказание за пропущенный стэндап тебе %d приседаний", intern.Username, pushUps)
+	expected := fmt.Sprintf("@%s в наказание за пропущенный стэндап тебе %s", intern.Username, i18n.Plural("ru", pushUps, "приседание", "приседания", "приседаний"))
 	assert.Equal(t, expected, text)
 	assert.NoError(t, b.db.DeleteIntern(intern.ID))
 }
 
+func TestExerciseRoll(t *testing.T) {
+	e := Exercise{ID: "pushups", Min: 1, Max: 2}
+	seen := map[int]bool{}
+	for i := 0; i < 100; i++ {
+		seen[e.roll()] = true
+	}
+	assert.Equal(t, map[int]bool{1: true, 2: true}, seen)
+}
+
 func TestPunishByPoetry(t *testing.T) {
-	b := setupTestBot(t)
+	b, _ := setupTestBot(t)
 	intern, err := b.db.CreateIntern(model.Intern{
 		Username: "testUser1",
 		Lives:    3,
@@ -195,11 +526,631 @@ func TestPunishByPoetry(t *testing.T) {
 	link, text, err := b.PunishByPoetry(intern, l)
 	assert.NoError(t, err)
 	fmt.Println(link)
-	expected := fmt.Sprintf("@%s в наказание за пропущенный стэндап прочитай этот стих: %v", intern.Username, link)
+	expected := fmt.Sprintf("@%s в наказание за пропущенный стэндап прочитай этот стих на весь офис: %v", intern.Username, link)
 	assert.Equal(t, expected, text)
 	assert.NoError(t, b.db.DeleteIntern(intern.ID))
 }
 
+func TestPunishmentRegistry(t *testing.T) {
+	coffee := Task{ID: "coffee", Text: "свари кофе всей команде"}
+	b, srv, chat, say := setupTestGroup(t, WithPunishment(coffee, 0))
+
+	picked := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		p, ok := b.punishments.Random()
+		assert.True(t, ok)
+		picked[p.Name()]++
+	}
+	for _, name := range []string{"pushups", "snowflakes", "removelives", "situps", "poetry"} {
+		assert.NotZero(t, picked[name], name)
+	}
+	assert.Zero(t, picked["coffee"])
+
+	assert.NoError(t, b.punishments.SetWeights("pushups:0, snowflakes:0,removelives:0,situps:0,poetry:0,coffee:5"))
+	for i := 0; i < 10; i++ {
+		p, _ := b.punishments.Random()
+		assert.Equal(t, "coffee", p.Name())
+	}
+	assert.Error(t, b.punishments.SetWeights("blogpost:1"))
+	assert.Error(t, b.punishments.SetWeights("coffee:-1"))
+	assert.Error(t, b.punishments.SetWeights("coffee"))
+
+	say("mentor", "@testbot_bot настройки наказание blogpost")
+	assert.Equal(t, []string{"не знаю такого наказания, выбери одно из: pushups, snowflakes, removelives, situps, poetry, coffee, random"}, srv.Messages(chat.ID))
+	say("mentor", "@testbot_bot настройки наказание random")
+	say("mentor", "@testbot_bot добавь @intern")
+	intern, _ := b.db.FindIntern("intern", chat.ID)
+	srv.Reset()
+	b.Punish(intern)
+	assert.Equal(t, []string{"@intern в наказание за пропущенный стэндап свари кофе всей команде"}, srv.Messages(chat.ID))
+
+	b.c.PunishmentType = "blogpost"
+	_, err := NewTGBot(b.c, WithStore(b.db), WithMessenger(b.tgAPI))
+	assert.Error(t, err)
+	b.c.PunishmentWeights = "poetry:much"
+	b.c.PunishmentType = "pushups"
+	_, err = NewTGBot(b.c, WithStore(b.db), WithMessenger(b.tgAPI))
+	assert.Error(t, err)
+}
+
+func TestPunishmentProof(t *testing.T) {
+	bishkek, _ := time.LoadLocation("Asia/Bishkek")
+	clock := newTestClock(time.Date(2026, time.October, 19, 10, 0, 0, 0, bishkek))
+	b, srv, chat, say := setupTestGroup(t, WithClock(clock.Now))
+	mentor := testUser("mentor")
+	internUser := testUser("intern")
+	proof := func(m tgbotapi.Message) {
+		m.MessageID = 900
+		m.From = internUser
+		m.Chat = chat
+		m.Photo = &[]tgbotapi.PhotoSize{{FileID: "pushups.jpg"}}
+		b.handleUpdate(tgbotapi.Update{Message: &m})
+	}
+	press := func(from *tgbotapi.User, data string) {
+		b.handleUpdate(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:      "q",
+			From:    from,
+			Message: &tgbotapi.Message{MessageID: 901, Chat: chat, Text: "ask"},
+			Data:    data,
+		}})
+	}
+	say("mentor", "@testbot_bot добавь @intern")
+	say("intern", "всем привет")
+	intern, _ := b.db.FindIntern("intern", chat.ID)
+	b.Punish(intern)
+	punishments, _ := b.db.ListPunishments(chat.ID)
+	assert.Equal(t, 1, len(punishments))
+	record := punishments[0]
+	assert.Equal(t, "pushups", record.Type)
+	assert.Equal(t, model.PunishmentPending, record.Status)
+	assert.Equal(t, intern.ID, record.InternID)
+	pushups := i18n.Plural("ru", record.Amount, "отжимание", "отжимания", "отжиманий")
+
+	// a photo without reply or mention is just a photo
+	srv.Reset()
+	proof(tgbotapi.Message{})
+	assert.Equal(t, 0, len(srv.Requests("sendMessage")))
+	// so is a reply to another message of the bot
+	proof(tgbotapi.Message{ReplyToMessage: &tgbotapi.Message{MessageID: record.MessageID + 1, From: &srv.Self}})
+	assert.Equal(t, 0, len(srv.Requests("sendMessage")))
+	record, _ = b.db.SelectPunishment(record.ID)
+	assert.Equal(t, model.PunishmentPending, record.Status)
+
+	proof(tgbotapi.Message{ReplyToMessage: &tgbotapi.Message{MessageID: record.MessageID, From: &srv.Self}})
+	record, _ = b.db.SelectPunishment(record.ID)
+	assert.Equal(t, model.PunishmentReview, record.Status)
+	assert.Equal(t, 900, record.ProofMessageID)
+	asks := srv.Requests("sendMessage")
+	assert.Equal(t, "@intern говорит, что выполнил наказание: "+pushups+". Засчитать?", asks[0].Params.Get("text"))
+	assert.Equal(t, "900", asks[0].Params.Get("reply_to_message_id"))
+	assert.Contains(t, asks[0].Params.Get("reply_markup"), fmt.Sprintf("punishment:confirm:%d", record.ID))
+	assert.Equal(t, "@intern, отправил менторам на проверку", asks[1].Params.Get("text"))
+
+	srv.Reset()
+	say("mentor", "@testbot_bot наказания")
+	assert.Equal(t, []string{"Не выполнено:\n@intern — " + pushups + " с 2026-10-19 (на проверке)"}, srv.Messages(chat.ID))
+
+	srv.Reset()
+	press(internUser, fmt.Sprintf("punishment:confirm:%d", record.ID))
+	// an admin without username does not let in other users without one
+	srv.SetAdmins(chat.ID, *mentor, tgbotapi.User{ID: 2, FirstName: "Anna"})
+	press(&tgbotapi.User{ID: 3, FirstName: "Guest"}, fmt.Sprintf("punishment:confirm:%d", record.ID))
+	for _, answer := range srv.Requests("answerCallbackQuery") {
+		assert.Equal(t, "Проверять наказания могут только админы группы", answer.Params.Get("text"))
+	}
+	press(mentor, fmt.Sprintf("punishment:reject:%d", record.ID))
+	record, _ = b.db.SelectPunishment(record.ID)
+	assert.Equal(t, model.PunishmentPending, record.Status)
+	assert.Equal(t, "ask\nне засчитано (@mentor)", srv.Requests("editMessageText")[0].Params.Get("text"))
+	assert.Equal(t, []string{"@intern, наказание не засчитано: " + pushups + ". Попробуй еще раз"}, srv.Messages(chat.ID))
+
+	srv.Reset()
+	proof(tgbotapi.Message{Caption: "@testbot_bot готово"})
+	press(mentor, fmt.Sprintf("punishment:confirm:%d", record.ID))
+	press(mentor, fmt.Sprintf("punishment:confirm:%d", record.ID))
+	record, _ = b.db.SelectPunishment(record.ID)
+	assert.Equal(t, model.PunishmentDone, record.Status)
+	assert.Equal(t, "Уже проверено", srv.Requests("answerCallbackQuery")[1].Params.Get("text"))
+	assert.Contains(t, srv.Messages(chat.ID), "@intern, наказание засчитано: "+pushups)
+
+	srv.Reset()
+	proof(tgbotapi.Message{Caption: "@testbot_bot еще"})
+	assert.Equal(t, []string{"@intern, не нашел, за какое это наказание"}, srv.Messages(chat.ID))
+
+	// unfinished punishments roll over into daily reports
+	b.Punish(intern)
+	srv.Reset()
+	assert.NoError(t, b.checkGroupStandups(b.groupSettings(chat.ID)))
+	report := srv.Messages(chat.ID)[1]
+	assert.Regexp(t, "^Каратель завершил свою работу ;\\)\nЕще не выполнено:\n@intern — \\d+ отжимани(е|я|й) с 2026-10-19$", report)
+
+	// only the latest ones are listed
+	for i := 0; i < unfinishedListed+1; i++ {
+		b.db.CreatePunishment(model.Punishment{
+			InternID: intern.ID,
+			GroupID:  chat.ID,
+			Type:     "pushups",
+			Amount:   10,
+			Issued:   time.Date(2026, time.October, 20, 4, i, 0, 0, time.UTC),
+			Status:   model.PunishmentPending,
+		})
+	}
+	srv.Reset()
+	say("mentor", "@testbot_bot наказания")
+	lines := strings.Split(srv.Messages(chat.ID)[0], "\n")
+	assert.Equal(t, unfinishedListed+2, len(lines))
+	assert.Equal(t, "@intern — 10 отжиманий с 2026-10-20", lines[1])
+	// two punishments of 2026-10-19 and the first one of 2026-10-20
+	assert.Equal(t, "и еще 3 раньше", lines[unfinishedListed+1])
+}
+
+func TestDebts(t *testing.T) {
+	b, srv, chat, say := setupTestGroup(t)
+	say("mentor", "@testbot_bot добавь @intern")
+	say("intern", "всем привет")
+	intern, _ := b.db.FindIntern("intern", chat.ID)
+	for i, p := range []model.Punishment{
+		{Type: "pushups", Amount: 73},
+		{Type: "situps", Amount: 40},
+		{Type: "pushups", Amount: 50},
+		{Type: "pushups", Amount: 20, Status: model.PunishmentDone},
+		{Type: "poetry", Amount: 1},
+	} {
+		p.InternID = intern.ID
+		p.GroupID = chat.ID
+		p.Issued = time.Date(2026, time.October, 19+i, 4, 0, 0, 0, time.UTC)
+		if p.Status == "" {
+			p.Status = model.PunishmentPending
+		}
+		b.db.CreatePunishment(p)
+	}
+
+	srv.Reset()
+	say("intern", "@testbot_bot долг")
+	say("intern", "@testbot_bot сделал 80 отжиманий")
+	say("intern", "@testbot_bot сделал 50 отжимания")
+	say("intern", "@testbot_bot сделал 5 pushups")
+	say("intern", "@testbot_bot сделал авторизацию, сегодня планирую тесты, проблем нет")
+	say("mentor", "@testbot_bot долг")
+	say("guest", "@testbot_bot долг")
+	assert.Equal(t, []string{
+		"@intern, твой долг: 123 отжимания, 40 приседаний",
+		"@intern, засчитал 80 отжиманий, осталось 43",
+		"@intern, засчитал 43 отжимания, осталось 0. Лишние 7 в запас не идут",
+		"@intern, 5 отжиманий ты никому не должен",
+		"@intern спасибо. Я принял твой стендап",
+		"Долги:\n@intern: 40 приседаний",
+		"@guest, ты не стажер, у тебя долгов нет",
+	}, srv.Messages(chat.ID))
+
+	punishments, _ := b.db.ListPunishments(chat.ID)
+	assert.Equal(t, model.PunishmentDone, punishments[0].Status)
+	assert.Equal(t, 73, punishments[0].Done)
+	assert.Equal(t, model.PunishmentDone, punishments[2].Status)
+	assert.Equal(t, model.PunishmentPending, punishments[4].Status)
+	standups, _ := b.db.ListStandups()
+	assert.Equal(t, 1, len(standups))
+}
+
+func TestLanguages(t *testing.T) {
+	b, srv, chat, say := setupTestGroup(t)
+	english := "@testbot_bot Yesterday I fixed the login page. Today I will write reports. Problems: none"
+
+	say("john", english)
+	say("mentor", "@testbot_bot настройки языки ky")
+	say("mentor", "@testbot_bot настройки языки ru, en")
+	say("john", english)
+	messages := srv.Messages(chat.ID)
+	assert.Equal(t, 4, len(messages))
+	assert.Contains(t, messages[0], "не принял стендап")
+	assert.Contains(t, messages[1], "языки стендапов нужно перечислить через запятую, доступны: en, ru")
+	assert.Contains(t, messages[2], "языки: ru,en")
+	assert.Equal(t, "@john спасибо. Я принял твой стендап", messages[3])
+
+	last, err := b.db.LastStandupFor("john", chat.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "none", last.Blockers)
+}
+
+func TestMessages(t *testing.T) {
+	b, srv, chat, say := setupTestGroup(t)
+	say("mentor", "@testbot_bot добавь @intern")
+	intern, _ := b.db.FindIntern("intern", chat.ID)
+
+	text, _ := b.takeLife(intern)
+	assert.Equal(t, "@intern осталось 2 жизни", text)
+	say("mentor", "@testbot_bot настройки локаль ky")
+	say("mentor", "@testbot_bot настройки локаль en")
+	text, _ = b.takeLife(intern)
+	assert.Equal(t, "@intern has 2 lives left", text)
+	assert.Equal(t, "@intern, as a punishment for the missed standup do 1 push-up", pushUps.localize(b, intern, 1))
+
+	srv.Reset()
+	say("mentor", "@testbot_bot добавь @bob")
+	say("mentor", "@testbot_bot настройки жизни 0")
+	say("mentor", "@testbot_bot наказания")
+	assert.Equal(t, []string{
+		"@bob, I'm watching you.",
+		"жизни must be at least 1",
+		"All punishments are done",
+	}, srv.Messages(chat.ID))
+
+	srv.Reset()
+	say("intern", "@testbot_bot текст lives.left {{.user}}, минус жизнь")
+	say("mentor", "@testbot_bot текст nope.nope")
+	say("mentor", "@testbot_bot текст lives.left {{.user")
+	say("mentor", "@testbot_bot текст lives.left @{{.user}}, минус жизнь!\nОсталось: {{.lives}}")
+	say("mentor", "@testbot_bot текст lives.left")
+	assert.Equal(t, []string{
+		"Only group admins can change messages",
+		"I don't know message nope.nope",
+	}, srv.Messages(chat.ID)[:2])
+	assert.Contains(t, srv.Messages(chat.ID)[2], "Can't parse the template")
+	assert.Equal(t, "@{{.user}}, минус жизнь!\nОсталось: {{.lives}}", srv.Messages(chat.ID)[4])
+	text, _ = b.takeLife(intern)
+	assert.Equal(t, "@intern, минус жизнь!\nОсталось: 2", text)
+	// other groups are not affected
+	intern.GroupID = -200
+	text, _ = b.takeLife(intern)
+	assert.Equal(t, "@intern осталось 2 жизни", text)
+
+	srv.Reset()
+	say("mentor", "@testbot_bot текст lives.left сброс")
+	say("mentor", "@testbot_bot текст")
+	assert.Equal(t, "Restored the default message:\n"+messagesEN[msgLivesLeft], srv.Messages(chat.ID)[0])
+	assert.NotContains(t, srv.Messages(chat.ID)[1], "(changed)")
+	assert.Contains(t, srv.Messages(chat.ID)[1], "unit.pushups")
+}
+
+func TestExplainRejected(t *testing.T) {
+	_, srv, chat, say := setupTestGroup(t)
+
+	say("intern", "@testbot_bot Вчера работал, сегодня буду работать")
+	say("intern", "@testbot_bot привет")
+	say("intern", "@testbot_bot добавь @intern")
+	say("intern", "@testbot_bot шаблон")
+	messages := srv.Messages(chat.ID)
+	assert.Equal(t, []string{
+		"@intern, не принял стендап: не нашел проблем. Как писать стендап, покажет @testbot_bot шаблон",
+		"@intern, не принял стендап: не нашел что сделано вчера, планов на сегодня, проблем. Как писать стендап, покажет @testbot_bot шаблон",
+		"Это могут только админы группы",
+	}, messages[:3])
+
+	// the template itself is a standup
+	srv.Reset()
+	say("intern", messages[3])
+	assert.Equal(t, []string{"@intern спасибо. Я принял твой стендап"}, srv.Messages(chat.ID))
+}
+
+func TestEditedStandup(t *testing.T) {
+	b, srv, chat, _ := setupTestGroup(t)
+	edit := func(text string) {
+		b.handleUpdate(tgbotapi.Update{EditedMessage: &tgbotapi.Message{
+			MessageID: 10,
+			From:      testUser("intern"),
+			Chat:      chat,
+			Text:      text,
+		}})
+	}
+	standup := "@testbot_bot Вчера: тесты\nСегодня: деплой\nПроблемы: нет"
+
+	b.handleUpdate(tgbotapi.Update{Message: &tgbotapi.Message{
+		MessageID: 10,
+		From:      testUser("intern"),
+		Chat:      chat,
+		Text:      "@testbot_bot Вчера: тесты",
+	}})
+	edit(standup)
+	edit(strings.Replace(standup, "деплой", "релиз", 1))
+	edit("@testbot_bot Вчера: тесты")
+	messages := srv.Messages(chat.ID)
+	assert.Equal(t, 4, len(messages))
+	assert.Contains(t, messages[0], "не принял стендап")
+	assert.Equal(t, "@intern спасибо. Я принял твой стендап", messages[1])
+	assert.Equal(t, "@intern спасибо. исправления приняты.", messages[2])
+	assert.Contains(t, messages[3], "не принял стендап")
+
+	standups, err := b.db.ListStandups()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(standups))
+	assert.Equal(t, "релиз", standups[0].Today)
+	assert.Equal(t, chat.ID, standups[0].ChatID)
+	assert.Equal(t, 10, standups[0].MessageID)
+
+	// edits of messages that never mentioned the bot are ignored
+	srv.Reset()
+	b.handleUpdate(tgbotapi.Update{EditedMessage: &tgbotapi.Message{
+		MessageID: 11,
+		From:      testUser("intern"),
+		Chat:      chat,
+		Text:      "Вчера: тесты\nСегодня: деплой\nПроблемы: нет",
+	}})
+	assert.Equal(t, 0, len(srv.Messages(chat.ID)))
+}
+
+func TestCaptionStandup(t *testing.T) {
+	b, srv, chat, _ := setupTestGroup(t)
+	_, err := b.db.CreateIntern(model.Intern{Username: "intern", UserID: 42, GroupID: chat.ID, Lives: 3})
+	assert.NoError(t, err)
+	caption := "@testbot_bot Вчера: " + strings.Repeat("верстал экран настроек, ", 20) + "\nСегодня: деплой\nПроблемы: нет"
+	b.handleUpdate(tgbotapi.Update{Message: &tgbotapi.Message{
+		MessageID: 10,
+		From:      testUser("intern"),
+		Chat:      chat,
+		Caption:   caption,
+		Photo:     &[]tgbotapi.PhotoSize{{FileID: "small", Width: 90}, {FileID: "big", Width: 1280}},
+	}})
+	messages := srv.Messages(chat.ID)
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, "@intern спасибо. Я принял твой стендап", messages[0])
+
+	standups, err := b.db.ListStandups()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(standups))
+	assert.Equal(t, caption, standups[0].Comment)
+	assert.Equal(t, "деплой", standups[0].Today)
+	files, err := b.db.ListAttachments(standups[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Attachment{{ID: files[0].ID, StandupID: standups[0].ID, Type: model.AttachmentPhoto, FileID: "big"}}, files)
+}
+
+func TestSharedStandups(t *testing.T) {
+	b, srv, first, say := setupTestGroup(t)
+	second := &tgbotapi.Chat{ID: -200, Type: "supergroup"}
+	srv.SetAdmins(second.ID, *testUser("mentor"))
+	for _, chat := range []*tgbotapi.Chat{first, second} {
+		_, err := b.db.CreateIntern(model.Intern{Username: "intern", GroupID: chat.ID, Lives: 3})
+		assert.NoError(t, err)
+	}
+	say("intern", "@testbot_bot Вчера: тесты\nСегодня: деплой\nПроблемы: нет")
+	standups, err := b.db.ListStandups()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(standups))
+	assert.Equal(t, first.ID, standups[0].GroupID)
+
+	submitted := func(groupID int64) bool {
+		intern, err := b.db.FindIntern("intern", groupID)
+		assert.NoError(t, err)
+		ok, err := b.submittedToday(intern, b.now())
+		assert.NoError(t, err)
+		return ok
+	}
+	assert.True(t, submitted(first.ID))
+	assert.False(t, submitted(second.ID))
+
+	b.handleUpdate(tgbotapi.Update{Message: &tgbotapi.Message{
+		From: testUser("mentor"),
+		Chat: second,
+		Text: "@testbot_bot настройки общий да",
+	}})
+	assert.Contains(t, srv.Messages(second.ID)[0], "общий: да")
+	assert.True(t, submitted(second.ID))
+}
+
+func TestDigest(t *testing.T) {
+	bishkek, _ := time.LoadLocation("Asia/Bishkek")
+	clock := newTestClock(time.Date(2026, time.October, 19, 9, 0, 0, 0, bishkek))
+	b, srv := setupTestBot(t, WithClock(clock.Now))
+
+	group := model.Group{ID: -100, MentorsChat: -500, PunishTime: "10:00", Timezone: "Asia/Bishkek"}
+	_, err := b.db.CreateGroup(group)
+	assert.NoError(t, err)
+	alice, _ := b.db.CreateIntern(model.Intern{Username: "alice", GroupID: group.ID, Lives: 3})
+	bob, _ := b.db.CreateIntern(model.Intern{Username: "bob", GroupID: group.ID, Lives: 2})
+	b.db.CreateAbsence(model.Absence{InternID: bob.ID, GroupID: group.ID, Kind: model.AbsenceVacation,
+		Since: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC), Until: time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC)})
+	for _, s := range []struct {
+		day, hour, min int
+		blockers       string
+	}{
+		{19, 9, 0, "нет"},
+		{20, 9, 30, "нет доступа к серверу"},
+		{21, 10, 0, "Проблем нет"},
+		{23, 9, 30, "нет доступа к серверу"},
+	} {
+		clock.Set(time.Date(2026, time.October, s.day, s.hour, s.min, 0, 0, bishkek))
+		b.db.CreateStandup(model.Standup{Username: "alice", GroupID: group.ID, Blockers: s.blockers})
+	}
+	// standups of other groups do not count
+	b.db.CreateStandup(model.Standup{Username: "bob", GroupID: -200, Blockers: "нет"})
+	b.db.CreatePunishment(model.Punishment{InternID: alice.ID, GroupID: group.ID, Issued: time.Date(2026, time.October, 1, 4, 0, 0, 0, time.UTC)})
+	b.db.CreatePunishment(model.Punishment{InternID: alice.ID, GroupID: group.ID, Issued: time.Date(2026, time.October, 22, 4, 0, 0, 0, time.UTC)})
+
+	clock.Set(time.Date(2026, time.October, 23, 18, 0, 0, 0, bishkek))
+	b.scheduleChecks()
+	b.scheduleChecks()
+	messages := srv.Messages(group.MentorsChat)
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, "<b>Итоги недели 2026-10-17 — 2026-10-23</b>\n\n"+
+		"@alice: стендапы 4 из 5, наказания: 1, 3 жизни, в среднем в 09:30\n"+
+		"    <i>проблемы:</i> нет доступа к серверу\n"+
+		"@bob: стендапы 0 из 3, наказания: 0, 2 жизни, в среднем в —", messages[0])
+	assert.Equal(t, 0, len(srv.Messages(group.ID)))
+
+	// the tick of 18:00 next Friday was late
+	clock.Set(time.Date(2026, time.October, 30, 17, 59, 30, 0, bishkek))
+	b.scheduleChecks()
+	clock.Set(time.Date(2026, time.October, 30, 18, 1, 10, 0, bishkek))
+	b.scheduleChecks()
+	assert.Equal(t, 2, len(srv.Messages(group.MentorsChat)))
+}
+
+func TestDigestBlockers(t *testing.T) {
+	bishkek, _ := time.LoadLocation("Asia/Bishkek")
+	clock := newTestClock(time.Date(2026, time.October, 23, 9, 0, 0, 0, bishkek))
+	b, _ := setupTestBot(t, WithClock(clock.Now))
+	group := model.Group{ID: -100, Timezone: "Asia/Bishkek"}
+	b.db.CreateGroup(group)
+	b.db.CreateIntern(model.Intern{Username: "alice", GroupID: group.ID, Lives: 3})
+	for i := 0; i < 20; i++ {
+		b.db.CreateStandup(model.Standup{Username: "alice", GroupID: group.ID,
+			Blockers: fmt.Sprintf("%d %s", i, strings.Repeat("жду доступ к серверу ", 20))})
+	}
+	messages, err := b.digest(b.groupSettings(group.ID), clock.Now())
+	assert.NoError(t, err)
+	assert.True(t, len(messages) > 1)
+	quoted := 0
+	for _, text := range messages {
+		assert.True(t, utf8.RuneCountInString(text) <= messageLimit)
+		quoted += strings.Count(text, "<i>проблемы:</i>")
+	}
+	assert.Equal(t, 20, quoted)
+}
+
+func TestParseDigest(t *testing.T) {
+	day, at, err := parseDigest("пт 18:00")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Friday, day)
+	assert.Equal(t, "18:00", at)
+	_, at, err = parseDigest("нет")
+	assert.NoError(t, err)
+	assert.Equal(t, "", at)
+	_, _, err = parseDigest("пт,сб 18:00")
+	assert.Error(t, err)
+	_, _, err = parseDigest("пт")
+	assert.Error(t, err)
+}
+
+func TestSplit(t *testing.T) {
+	lines := []string{"title", "", strings.Repeat("a", 10), strings.Repeat("b", 10)}
+	assert.Equal(t, []string{"title\n\n" + strings.Repeat("a", 10), strings.Repeat("b", 10)}, split(lines, 20))
+	assert.Equal(t, []string{strings.Join(lines, "\n")}, split(lines, messageLimit))
+	assert.Equal(t, []string{}, split(nil, messageLimit))
+	assert.Equal(t, "abc…", truncate("abcdef", 4))
+	assert.Equal(t, "abc", truncate("abc", 4))
+}
+
+func TestCheckHTML(t *testing.T) {
+	assert.NoError(t, checkHTML(messagesRU[msgDigestBlockers]))
+	assert.NoError(t, checkHTML(`<b>{{.user}}</b> &lt;3 &amp; <a href="https://example.com">link</a>`))
+	for _, text := range []string{
+		`{{.user}} < 3`,
+		`<b>{{.user}}`,
+		`<b>{{.user}}</i>`,
+		`<script>{{.user}}</script>`,
+		`{{.user}} & co`,
+	} {
+		assert.Error(t, checkHTML(text), text)
+	}
+}
+
+func TestExport(t *testing.T) {
+	b, srv, chat, say := setupTestGroup(t)
+	b.db.CreateStandup(model.Standup{Username: "intern", GroupID: chat.ID, Comment: "standup"})
+	b.db.CreateStandup(model.Standup{Username: "other", GroupID: chat.ID, Comment: "standup"})
+
+	say("intern", "@testbot_bot экспорт")
+	say("mentor", "@testbot_bot экспорт @intern вчера")
+	messages := srv.Messages(chat.ID)
+	assert.Equal(t, "Это могут только админы группы", messages[0])
+	assert.Contains(t, messages[1], "Формат: экспорт [@user]")
+
+	say("mentor", "@testbot_bot экспорт @intern md")
+	documents := srv.Requests("sendDocument")
+	assert.Equal(t, 1, len(documents))
+	assert.Equal(t, chat.ID, documents[0].ChatID())
+	journal := string(documents[0].Files["document"])
+	assert.Contains(t, journal, "# Журнал стендапов @intern")
+	assert.Contains(t, journal, "стендап\n  standup")
+	assert.NotContains(t, journal, "other")
+
+	say("mentor", "@testbot_bot настройки локаль en")
+	say("mentor", "@testbot_bot экспорт @intern md")
+	documents = srv.Requests("sendDocument")
+	assert.Equal(t, 2, len(documents))
+	journal = string(documents[1].Files["document"])
+	assert.Contains(t, journal, "# Standup journal @intern")
+	assert.Contains(t, journal, "standup\n  standup")
+}
+
+func TestMetrics(t *testing.T) {
+	d := time.Date(2026, time.October, 19, 8, 0, 0, 0, time.UTC)
+	b, _, chat, say := setupTestGroup(t, WithClock(func() time.Time { return d }),
+		WithPunishment(Exercise{ID: "squats", Min: 10, Max: 10, Text: "%s %d приседаний"}, 1))
+	_, err := b.db.CreateGroup(model.Group{ID: chat.ID, PunishmentType: "squats"})
+	assert.NoError(t, err)
+	_, err = b.db.CreateIntern(model.Intern{Username: "intern", GroupID: chat.ID, Lives: 3})
+	assert.NoError(t, err)
+	updates := testutil.ToFloat64(metrics.UpdatesReceived)
+	accepted := testutil.ToFloat64(metrics.StandupsAccepted)
+	rejected := testutil.ToFloat64(metrics.StandupsRejected.WithLabelValues("missing_blockers"))
+	squats := testutil.ToFloat64(metrics.PunishmentsIssued.WithLabelValues("squats"))
+	for _, text := range []string{
+		"@testbot_bot Вчера работал, сегодня буду работать",
+		"@testbot_bot Вчера: тесты\nСегодня: деплой\nПроблемы: нет",
+	} {
+		say("intern", text)
+	}
+	assert.Equal(t, updates+2, testutil.ToFloat64(metrics.UpdatesReceived))
+	assert.Equal(t, accepted+1, testutil.ToFloat64(metrics.StandupsAccepted))
+	assert.Equal(t, rejected+1, testutil.ToFloat64(metrics.StandupsRejected.WithLabelValues("missing_blockers")))
+
+	_, err = b.db.CreateIntern(model.Intern{Username: "lazy", GroupID: chat.ID, Lives: 3})
+	assert.NoError(t, err)
+	b.dailyJob(b.groupSettings(chat.ID))
+	assert.Equal(t, squats+1, testutil.ToFloat64(metrics.PunishmentsIssued.WithLabelValues("squats")))
+	assert.Equal(t, float64(d.Unix()), testutil.ToFloat64(metrics.DailyJobLastSuccess.WithLabelValues("-100")))
+}
+
+func TestRejectReason(t *testing.T) {
+	assert.Equal(t, "missing_yesterday_today_blockers", rejectReason([]standup.Section{standup.Yesterday, standup.Today, standup.Blockers}))
+	assert.Equal(t, "invalid", rejectReason(nil))
+}
+
+func TestHealthChecks(t *testing.T) {
+	clock := newTestClock(time.Now())
+	b, _ := setupTestBot(t, WithClock(clock.Now))
+	assert.NoError(t, b.CheckPolling())
+	assert.EqualError(t, b.CheckScheduler(), "no scheduler tick yet")
+	b.scheduleChecks()
+	assert.NoError(t, b.CheckScheduler())
+
+	clock.Add(5 * time.Minute)
+	assert.EqualError(t, b.CheckScheduler(), "last scheduler tick was 5m0s ago")
+}
+
+func TestStreaks(t *testing.T) {
+	bishkek, _ := time.LoadLocation("Asia/Bishkek")
+	clock := newTestClock(time.Date(2026, time.October, 23, 9, 0, 0, 0, bishkek))
+	b, srv, chat, say := setupTestGroup(t, WithClock(clock.Now))
+	say("mentor", "@testbot_bot настройки наказание removelives")
+	say("mentor", "@testbot_bot настройки бонус 2")
+	say("mentor", "@testbot_bot настройки максимум 4")
+	say("mentor", "@testbot_bot добавь @vasya")
+	say("mentor", "@testbot_bot добавь @petya")
+
+	// fri, sat, sun, mon, tue, wed, thu
+	for i, submitters := range [][]string{{"vasya", "petya"}, {}, {}, {"vasya", "petya"}, {"vasya", "petya"}, {"vasya", "petya"}, {"petya"}} {
+		clock.Set(time.Date(2026, time.October, 23+i, 9, 0, 0, 0, bishkek))
+		for _, name := range submitters {
+			b.db.CreateStandup(model.Standup{Username: name, GroupID: chat.ID, Comment: "standup"})
+		}
+		if i == 3 {
+			srv.Reset()
+		}
+		clock.Add(time.Hour)
+		b.checkGroupStandups(b.groupSettings(chat.ID))
+		if i == 3 {
+			assert.Equal(t, []string{
+				"@vasya, 2 стендапа подряд! Держи бонусную жизнь, теперь у тебя 4 жизни",
+				"@petya, 2 стендапа подряд! Держи бонусную жизнь, теперь у тебя 4 жизни",
+				"Каратель завершил свою работу ;)",
+			}, srv.Messages(chat.ID))
+		}
+	}
+	say("mentor", "@testbot_bot добавь @masha")
+	srv.Reset()
+	say("mentor", "@testbot_bot рейтинг")
+	assert.Equal(t, []string{"Рейтинг:\n" +
+		"1. @petya — подряд: 5, вовремя: 100% (5/5), жизней: 4\n" +
+		"2. @vasya — подряд: 0, вовремя: 80% (4/5), жизней: 3\n" +
+		"3. @masha — подряд: 0, вовремя: —, жизней: 3"}, srv.Messages(chat.ID))
+
+	say("mentor", "@testbot_bot настройки бонус нет")
+	assert.Equal(t, -1, b.groupSettings(chat.ID).StreakBonus)
+	assert.Contains(t, srv.Messages(chat.ID)[1], "бонус: нет")
+}
+
 func TestPoetryExist(t *testing.T) {
 	var testCases = []struct {
 		poetrylink string
@@ -218,7 +1169,7 @@ func TestPoetryExist(t *testing.T) {
 }
 
 func TestPunishFunc(t *testing.T) {
-	b := setupTestBot(t)
+	b, _ := setupTestBot(t)
 	i, err := b.db.CreateIntern(model.Intern{
 		Username: "user",
 		Lives:    3,
@@ -239,29 +1190,91 @@ func TestPunishFunc(t *testing.T) {
 
 }
 
-func setupTestBot(t *testing.T) *Bot {
+func setupTestBot(t *testing.T, opts ...Option) (*Bot, *telegramtest.Server) {
 	os.Setenv("BOT_TELEGRAM_TOKEN", BotToken)
 	os.Setenv("BOT_INTERNS_CHAT_ID", BotChat)
 	os.Setenv("BOT_DATABASE_URL", BotDatabaseURL)
 	conf, err := config.GetConfig()
 	assert.NoError(t, err)
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
 
-	r := httpmock.NewStringResponder(200, `{
-		"ok": true,
-		"result": {
-		  "id": 1000,
-		  "is_bot": true,
-		  "first_name": "testBot",
-		  "username": "testbot_bot"
+	srv := telegramtest.NewServer("testbot_bot")
+	srv.Token = conf.TelegramToken
+	t.Cleanup(srv.Close)
+	api, err := srv.NewBotAPI()
+	assert.NoError(t, err)
+
+	db := storage.NewMemory()
+	bot, err := NewTGBot(conf, append([]Option{WithStore(db), WithMessenger(api)}, opts...)...)
+	assert.NoError(t, err)
+	// standups are stamped by the clock of the bot
+	db.Now = bot.now
+	return bot, srv
+}
+
+// setupTestGroup starts a bot in a supergroup where mentor is an admin. say
+// posts text to the group on behalf of the test user with username, the
+// message goes through getUpdates of the fake server.
+func setupTestGroup(t *testing.T, opts ...Option) (*Bot, *telegramtest.Server, *tgbotapi.Chat, func(username, text string)) {
+	b, srv := setupTestBot(t, opts...)
+	chat := &tgbotapi.Chat{ID: -100, Type: "supergroup"}
+	srv.SetAdmins(chat.ID, *testUser("mentor"))
+	messageID := 0
+	say := func(username, text string) {
+		messageID++
+		srv.Inject(tgbotapi.Update{Message: &tgbotapi.Message{
+			MessageID: messageID,
+			From:      testUser(username),
+			Chat:      chat,
+			Text:      text,
+		}})
+		select {
+		case update := <-b.updates:
+			b.handleUpdate(update)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("bot did not get %q", text)
 		}
-	  }`)
+	}
+	return b, srv, chat, say
+}
 
-	url := fmt.Sprintf("https://api.telegram.org/bot%v/getMe", conf.TelegramToken)
-	httpmock.RegisterResponder("POST", url, r)
+// testUsers are Telegram IDs of users talking to the bot in tests
+var testUsers = map[string]int{"mentor": 1, "guest": 7, "intern": 42, "john": 43}
 
-	bot, err := NewTGBot(conf)
-	assert.NoError(t, err)
-	return bot
+// testUser returns the Telegram user with username from testUsers
+func testUser(username string) *tgbotapi.User {
+	id, ok := testUsers[username]
+	if !ok {
+		panic("unknown test user " + username)
+	}
+	return &tgbotapi.User{ID: id, UserName: username}
+}
+
+// testClock is a clock tests move by hand, see WithClock. The poll goroutine
+// reads it too, so it is guarded.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock(now time.Time) *testClock {
+	return &testClock{now: now}
+}
+
+// Now returns the time the clock was set to
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to now
+func (c *testClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Add moves the clock by d
+func (c *testClock) Add(d time.Duration) {
+	c.Set(c.Now().Add(d))
 }
@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/maddevsio/punisher/calendar"
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/model"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// upcomingDaysOff is how far ahead "@bot выходные" looks
+const upcomingDaysOff = 30
+
+// dayOff reports whether t is not a working day for the group. Reason is
+// empty for regular weekends and names the holiday or day off otherwise.
+func (b *Bot) dayOff(group model.Group, t time.Time) (string, bool) {
+	workdays, err := calendar.ParseWeekdays(group.Workdays)
+	if err != nil {
+		workdays = calendar.DefaultWorkdays
+	}
+	if !calendar.IsWorkday(workdays, t) {
+		return "", true
+	}
+	if h, ok := b.holidays.Holiday(t); ok {
+		return h.Name, true
+	}
+	daysOff, err := b.db.ListDaysOff(group.ID)
+	if err != nil {
+		logrus.Errorf("ListDaysOff failed: %v\n", err)
+		return "", false
+	}
+	today := calendar.Day(t)
+	for _, d := range daysOff {
+		if d.Date.Equal(today) {
+			if d.Reason == "" {
+				return b.t(group.ID, msgDayOffWeekend, nil), true
+			}
+			return d.Reason, true
+		}
+	}
+	return "", false
+}
+
+// dayOffCommand handles "выходной <дата> [причина]" and "рабочий <дата>"
+func (b *Bot) dayOffCommand(channel int64, command string, args []string) {
+	if len(args) == 0 {
+		b.say(channel, msgDayOffFormat, i18n.Vars{"command": command})
+		return
+	}
+	date, err := calendar.ParseDate(args[0])
+	if err != nil {
+		b.say(channel, msgDateFormat, nil)
+		return
+	}
+	daysOff, err := b.db.ListDaysOff(channel)
+	if err != nil {
+		logrus.Errorf("ListDaysOff failed: %v\n", err)
+		return
+	}
+	for _, d := range daysOff {
+		if !d.Date.Equal(date) {
+			continue
+		}
+		if command == "выходной" {
+			b.say(channel, msgDayOffAlready, i18n.Vars{"date": args[0]})
+			return
+		}
+		if err := b.db.DeleteDayOff(d.ID); err != nil {
+			logrus.Errorf("DeleteDayOff failed: %v\n", err)
+			return
+		}
+		b.say(channel, msgDayOffRemoved, i18n.Vars{"date": args[0]})
+		return
+	}
+	if command == "рабочий" {
+		b.say(channel, msgDayOffNotOff, i18n.Vars{"date": args[0]})
+		return
+	}
+	_, err = b.db.CreateDayOff(model.DayOff{
+		GroupID: channel,
+		Date:    date,
+		Reason:  strings.Join(args[1:], " "),
+	})
+	if err != nil {
+		logrus.Errorf("CreateDayOff failed: %v\n", err)
+		return
+	}
+	b.say(channel, msgDayOffAdded, i18n.Vars{"date": args[0]})
+}
+
+// listDaysOff posts holidays and days off of the group for the next month
+// along with current and upcoming absences of interns
+func (b *Bot) listDaysOff(channel int64) {
+	group := b.groupSettings(channel)
+	today := calendar.Day(b.now().In(b.location(group)))
+	lines := []string{}
+	for i := 0; i < upcomingDaysOff; i++ {
+		day := today.AddDate(0, 0, i)
+		if reason, off := b.dayOff(group, day); off && reason != "" {
+			lines = append(lines, fmt.Sprintf("%s — %s", day.Format("2006-01-02"), reason))
+		}
+	}
+	text := b.t(channel, msgDaysOff, nil) + "\n" + strings.Join(lines, "\n")
+	if len(lines) == 0 {
+		text = b.t(channel, msgDaysOffNone, i18n.Vars{"workdays": group.Workdays})
+	}
+	interns, err := b.db.ListGroupInterns(channel)
+	if err != nil {
+		logrus.Errorf("ListGroupInterns failed: %v\n", err)
+	}
+	absences, err := b.absencesFrom(channel, today)
+	if err != nil {
+		logrus.Errorf("ListAbsences failed: %v\n", err)
+	}
+	if absent := b.formatAbsences(interns, absences); len(absent) > 0 {
+		text += "\n\n" + b.t(channel, msgDaysOffAbsent, nil) + "\n" + strings.Join(absent, "\n")
+	}
+	b.tgAPI.Send(tgbotapi.NewMessage(channel, text))
+}
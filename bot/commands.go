@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"strings"
+
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/model"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// adminCommands may be run by group admins only
+var adminCommands = map[string]bool{
+	"добавь":   true,
+	"удали":    true,
+	"выходной": true,
+	"рабочий":  true,
+	"отпуск":   true,
+	"болеет":   true,
+	"вернулся": true,
+	"экспорт":  true,
+}
+
+// handleCommand runs "@bot <command> [args...]" messages.
+// It returns false when message is not a command, so it may be a standup.
+// Admin commands sent by others are refused rather than taken for standups.
+func (b *Bot) handleCommand(message *tgbotapi.Message, isAdmin bool) bool {
+	s := strings.Fields(message.Text)
+	if len(s) < 2 || s[0] != "@"+b.self.UserName {
+		return false
+	}
+	channel := message.Chat.ID
+	command, args := s[1], s[2:]
+	if adminCommands[command] && !isAdmin {
+		b.say(channel, msgAdminsOnly, nil)
+		return true
+	}
+	switch command {
+	case "добавь", "удали":
+		if len(args) == 0 {
+			b.say(channel, msgInternFormat, i18n.Vars{"command": command})
+			break
+		}
+		if command == "добавь" {
+			b.addIntern(channel, args[0])
+		} else {
+			b.removeIntern(channel, args[0])
+		}
+	case "настройки":
+		b.groupSettingsCommand(channel, args, isAdmin)
+	case "наказания":
+		b.listPunishments(channel)
+	case "сделал", "сделала":
+		// "сделал авторизацию, сегодня ..." is a standup, not a debt report
+		n, e, ok := b.parseDone(args)
+		if !ok {
+			return false
+		}
+		b.reportDone(message, n, e)
+	case "долг":
+		b.debtCommand(message, isAdmin)
+	case "текст":
+		b.textCommand(message, args, isAdmin)
+	case "рейтинг":
+		b.rating(channel)
+	case "выходные":
+		b.listDaysOff(channel)
+	case "выходной", "рабочий":
+		b.dayOffCommand(channel, command, args)
+	case "отпуск", "болеет":
+		b.absenceCommand(channel, command, args)
+	case "вернулся":
+		b.returnedCommand(channel, args)
+	case "шаблон", "template":
+		b.templateCommand(channel)
+	case "экспорт":
+		b.exportCommand(channel, args)
+	default:
+		return false
+	}
+	return true
+}
+
+func (b *Bot) addIntern(channel int64, name string) {
+	logrus.Infof("Add intern: %s to DB\n", name)
+	username := strings.Replace(name, "@", "", -1)
+	intern := model.Intern{Username: username, Lives: b.groupSettings(channel).Lives, GroupID: channel}
+	_, err := b.db.FindIntern(username, channel)
+	if err != nil {
+		_, err := b.db.CreateIntern(intern)
+		if err != nil {
+			logrus.Errorf("CreateIntern failed: %v", err)
+			b.say(channel, msgInternNotAdded, i18n.Vars{"user": intern.Username})
+			return
+		}
+		b.say(channel, msgInternAdded, i18n.Vars{"user": intern.Username})
+	} else {
+		b.say(channel, msgInternAlready, i18n.Vars{"user": intern.Username})
+	}
+}
+
+func (b *Bot) removeIntern(channel int64, name string) {
+	logrus.Infof("Remove intern: %s from DB\n", name)
+	username := strings.Replace(name, "@", "", -1)
+	intern, err := b.db.FindIntern(username, channel)
+	if err != nil {
+		logrus.Errorf("FindIntern failed: %v", err)
+		b.say(channel, msgInternUnknown, i18n.Vars{"user": username})
+		return
+	}
+	err = b.db.DeleteIntern(intern.ID)
+	if err != nil {
+		logrus.Errorf("DeleteIntern failed: %v", err)
+		b.say(channel, msgInternNotRemoved, i18n.Vars{"user": intern.Username})
+		return
+	}
+	b.say(channel, msgInternRemoved, i18n.Vars{"user": intern.Username})
+}
@@ -0,0 +1,208 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/model"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// exercise returns registered exercise by name
+func (b *Bot) exercise(name string) (Exercise, bool) {
+	p, _ := b.punishments.Get(name)
+	e, ok := p.(Exercise)
+	return e, ok
+}
+
+// exerciseByWord finds exercise by its name or a form of its unit,
+// e.g. "отжиманий", "отжимания", "push-ups" and "pushups" all mean push-ups
+func (b *Bot) exerciseByWord(word string) (Exercise, bool) {
+	word = strings.ToLower(word)
+	for _, name := range b.punishments.Names() {
+		e, ok := b.exercise(name)
+		if !ok {
+			continue
+		}
+		if word == e.ID || contains(b.unitForms(e), word) {
+			return e, true
+		}
+		// drop the ending, Russian words change it with the number
+		stem := []rune(e.Unit)
+		if len(stem) > 5 {
+			stem = stem[:len(stem)-2]
+		}
+		if len(stem) > 0 && strings.HasPrefix(word, string(stem)) {
+			return e, true
+		}
+	}
+	return Exercise{}, false
+}
+
+// unitForms returns plural forms of the exercise's unit in every locale of
+// the catalog
+func (b *Bot) unitForms(e Exercise) []string {
+	forms := []string{}
+	if !b.catalog.Has("unit." + e.ID) {
+		return forms
+	}
+	for _, locale := range b.catalog.Locales() {
+		// 1, 2 and 5 pick every form in the plural rules we have
+		for _, n := range []int{1, 2, 5} {
+			text, err := i18n.Render(locale, b.catalog.Text(locale, "unit."+e.ID), i18n.Vars{"n": n})
+			if err != nil {
+				continue
+			}
+			forms = append(forms, strings.ToLower(strings.TrimSpace(strings.TrimPrefix(text, strconv.Itoa(n)))))
+		}
+	}
+	return forms
+}
+
+// parseDone parses "<N> <упражнений>" of a debt report, ok is false when
+// args are something else, e.g. a standup starting with "сделал"
+func (b *Bot) parseDone(args []string) (int, Exercise, bool) {
+	if len(args) < 2 {
+		return 0, Exercise{}, false
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, Exercise{}, false
+	}
+	e, ok := b.exerciseByWord(strings.TrimRight(args[1], ".,!"))
+	return n, e, ok
+}
+
+// reportDone handles "сделал <N> <упражнений>" reducing intern's debt
+// starting from the oldest punishment
+func (b *Bot) reportDone(message *tgbotapi.Message, n int, e Exercise) {
+	channel := message.Chat.ID
+	intern, err := b.db.FindInternByUserID(int64(message.From.ID), channel)
+	if err != nil {
+		b.say(channel, msgDebtNotIntern, i18n.Vars{"user": message.From.UserName})
+		return
+	}
+	punishments, err := b.db.ListPunishments(channel)
+	if err != nil {
+		logrus.Errorf("ListPunishments failed: %v\n", err)
+		return
+	}
+	left := n
+	for _, p := range punishments {
+		if left == 0 {
+			break
+		}
+		if p.InternID != intern.ID || p.Type != e.ID || p.Status == model.PunishmentDone {
+			continue
+		}
+		done := p.Amount - p.Done
+		if done > left {
+			done = left
+		}
+		p.Done += done
+		left -= done
+		if p.Done == p.Amount {
+			p.Status = model.PunishmentDone
+		}
+		if _, err := b.db.UpdatePunishment(p); err != nil {
+			logrus.Errorf("UpdatePunishment failed: %v\n", err)
+			return
+		}
+	}
+	if left == n {
+		b.say(channel, msgDebtNothingOwed, i18n.Vars{"user": intern.Username, "amount": b.amount(channel, e, n)})
+		return
+	}
+	debts, err := b.debts(channel)
+	if err != nil {
+		logrus.Errorf("debts failed: %v\n", err)
+		return
+	}
+	b.say(channel, msgDebtCounted, i18n.Vars{
+		"user":   intern.Username,
+		"amount": b.amount(channel, e, n-left),
+		"left":   debts[intern.ID][e.ID],
+		"extra":  left,
+	})
+}
+
+// debts sums what interns of the group still have to do by exercise:
+// intern ID -> exercise name -> repetitions
+func (b *Bot) debts(groupID int64) (map[int64]map[string]int, error) {
+	punishments, err := b.db.ListPunishments(groupID)
+	if err != nil {
+		return nil, err
+	}
+	debts := map[int64]map[string]int{}
+	for _, p := range punishments {
+		if p.Status == model.PunishmentDone {
+			continue
+		}
+		if _, ok := b.exercise(p.Type); !ok {
+			continue
+		}
+		if debts[p.InternID] == nil {
+			debts[p.InternID] = map[string]int{}
+		}
+		debts[p.InternID][p.Type] += p.Amount - p.Done
+	}
+	return debts, nil
+}
+
+// formatDebt formats debt of one intern, e.g. "120 отжиманий, 40 приседаний"
+func (b *Bot) formatDebt(groupID int64, debt map[string]int) string {
+	names := []string{}
+	for name := range debt {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := []string{}
+	for _, name := range names {
+		e, _ := b.exercise(name)
+		parts = append(parts, b.amount(groupID, e, debt[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// debtCommand shows debt of the sender or, for admins, of the whole group
+func (b *Bot) debtCommand(message *tgbotapi.Message, isAdmin bool) {
+	channel := message.Chat.ID
+	debts, err := b.debts(channel)
+	if err != nil {
+		logrus.Errorf("debts failed: %v\n", err)
+		return
+	}
+	if !isAdmin {
+		intern, err := b.db.FindInternByUserID(int64(message.From.ID), channel)
+		if err != nil {
+			b.say(channel, msgDebtStranger, i18n.Vars{"user": message.From.UserName})
+			return
+		}
+		if len(debts[intern.ID]) == 0 {
+			b.say(channel, msgDebtNoneOwn, i18n.Vars{"user": intern.Username})
+			return
+		}
+		b.say(channel, msgDebtOwn, i18n.Vars{"user": intern.Username, "debt": b.formatDebt(channel, debts[intern.ID])})
+		return
+	}
+	interns, err := b.db.ListGroupInterns(channel)
+	if err != nil {
+		logrus.Errorf("ListGroupInterns failed: %v\n", err)
+		return
+	}
+	lines := []string{}
+	for _, intern := range interns {
+		if len(debts[intern.ID]) > 0 {
+			lines = append(lines, fmt.Sprintf("@%s: %s", intern.Username, b.formatDebt(channel, debts[intern.ID])))
+		}
+	}
+	if len(lines) == 0 {
+		b.say(channel, msgDebtNone, nil)
+		return
+	}
+	b.tgAPI.Send(tgbotapi.NewMessage(channel, b.t(channel, msgDebts, nil)+"\n"+strings.Join(lines, "\n")))
+}
@@ -0,0 +1,256 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/maddevsio/punisher/calendar"
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/model"
+	"github.com/maddevsio/punisher/standup"
+	"github.com/maddevsio/punisher/storage"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// digestDays is how many days, today included, the weekly digest covers
+const digestDays = 7
+
+// noDigest is the value of DIGEST that disables weekly digests
+const noDigest = "нет"
+
+// messageLimit is how many characters Telegram accepts in one message
+const messageLimit = 4096
+
+// blockerLimit is how many characters of every blocker the digest quotes,
+// every blocker takes a line of its own so that lines fit into a message
+const blockerLimit = 300
+
+// parseDigest parses weekday and time of the weekly digest, e.g. "пт 18:00".
+// Time is empty when digests are disabled.
+func parseDigest(s string) (time.Weekday, string, error) {
+	if strings.TrimSpace(s) == noDigest {
+		return 0, "", nil
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return 0, "", errors.New("digest needs weekday and time, e.g. пт 18:00")
+	}
+	days, err := calendar.ParseWeekdays(fields[0])
+	if err != nil || len(days) != 1 {
+		return 0, "", fmt.Errorf("bad weekday %q", fields[0])
+	}
+	t, err := time.Parse("15:04", fields[1])
+	if err != nil {
+		return 0, "", fmt.Errorf("bad time %q", fields[1])
+	}
+	return days[0], t.Format("15:04"), nil
+}
+
+// internDigest is a week of an intern
+type internDigest struct {
+	intern model.Intern
+	// expected are working days intern was not absent, submitted are those
+	// of them with a standup
+	expected, submitted int
+	punishments         int
+	// minutes are times of the first standup of every day, in minutes
+	// after midnight in the group's timezone
+	minutes  []int
+	blockers []string
+}
+
+// average returns average submission time as "15:04", or "—" without standups
+func (d internDigest) average() string {
+	if len(d.minutes) == 0 {
+		return "—"
+	}
+	sum := 0
+	for _, m := range d.minutes {
+		sum += m
+	}
+	avg := sum / len(d.minutes)
+	return fmt.Sprintf("%02d:%02d", avg/60, avg%60)
+}
+
+// weekDigest sums up the week ending on now for every intern of the group
+func (b *Bot) weekDigest(group model.Group, now time.Time) ([]internDigest, error) {
+	loc := now.Location()
+	first := now.AddDate(0, 0, 1-digestDays)
+	since := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc)
+	interns, err := b.db.ListGroupInterns(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	standups, err := b.db.FindStandups(storage.StandupFilter{Since: since})
+	if err != nil {
+		return nil, err
+	}
+	punishments, err := b.db.ListPunishments(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	absences, err := b.absencesFrom(group.ID, calendar.Day(since))
+	if err != nil {
+		return nil, err
+	}
+	// groups of every intern, standups posted there count when group shares them
+	enrolled := map[string]map[int64]bool{}
+	if enabled(group.SharedStandups) {
+		for _, intern := range interns {
+			entries, err := b.db.FindInternsByUsername(intern.Username)
+			if err != nil {
+				return nil, err
+			}
+			enrolled[intern.Username] = map[int64]bool{}
+			for _, i := range entries {
+				enrolled[intern.Username][i.GroupID] = true
+			}
+		}
+	}
+	workdays := map[string]bool{}
+	for day := since; !day.After(now); day = day.AddDate(0, 0, 1) {
+		if _, off := b.dayOff(group, day); !off {
+			workdays[day.Format("2006-01-02")] = true
+		}
+	}
+	parser := b.languages.Parser(group.Languages)
+
+	digests := []internDigest{}
+	for _, intern := range interns {
+		d := internDigest{intern: intern}
+		expected := map[string]bool{}
+		for day := range workdays {
+			date, _ := calendar.ParseDate(day)
+			if _, ok := activeAbsence(absences[intern.ID], date); !ok {
+				expected[day] = true
+			}
+		}
+		d.expected = len(expected)
+		seen := map[string]bool{}
+		for _, s := range standups {
+			if s.Username != intern.Username || !(s.GroupID == group.ID || enrolled[s.Username][s.GroupID]) {
+				continue
+			}
+			created := s.Created.In(loc)
+			if s.Blockers != "" && !parser.Empty(standup.Blockers, s.Blockers) && !contains(d.blockers, s.Blockers) {
+				d.blockers = append(d.blockers, s.Blockers)
+			}
+			day := created.Format("2006-01-02")
+			if seen[day] {
+				continue
+			}
+			seen[day] = true
+			d.minutes = append(d.minutes, created.Hour()*60+created.Minute())
+			if expected[day] {
+				d.submitted++
+			}
+		}
+		for _, p := range punishments {
+			if p.InternID == intern.ID && !p.Issued.Before(since) {
+				d.punishments++
+			}
+		}
+		digests = append(digests, d)
+	}
+	return digests, nil
+}
+
+// digest renders the weekly digest of the group split into messages, there
+// are none when the group has no interns
+func (b *Bot) digest(group model.Group, now time.Time) ([]string, error) {
+	digests, err := b.weekDigest(group, now)
+	if err != nil || len(digests) == 0 {
+		return nil, err
+	}
+	lines := []string{b.t(group.ID, msgDigestTitle, i18n.Vars{
+		"since": now.AddDate(0, 0, 1-digestDays).Format("2006-01-02"),
+		"until": now.Format("2006-01-02"),
+	}), ""}
+	for _, d := range digests {
+		lines = append(lines, b.t(group.ID, msgDigestIntern, i18n.Vars{
+			"user":        html.EscapeString(d.intern.Username),
+			"submitted":   d.submitted,
+			"expected":    d.expected,
+			"punishments": d.punishments,
+			"lives":       d.intern.Lives,
+			"average":     d.average(),
+		}))
+		for _, blocker := range d.blockers {
+			lines = append(lines, b.t(group.ID, msgDigestBlockers, i18n.Vars{
+				"blockers": html.EscapeString(truncate(blocker, blockerLimit)),
+			}))
+		}
+	}
+	return split(lines, messageLimit), nil
+}
+
+// truncate cuts s to n characters ending it with "…"
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// split joins lines into messages of at most limit characters, lines are not
+// broken as they may hold HTML tags
+func split(lines []string, limit int) []string {
+	messages := []string{}
+	current := []string{}
+	size := 0
+	for _, line := range lines {
+		n := utf8.RuneCountInString(line)
+		if len(current) > 0 && size+1+n > limit {
+			messages = append(messages, strings.Join(current, "\n"))
+			current, size = nil, 0
+		}
+		if len(current) > 0 {
+			size++
+		}
+		current = append(current, line)
+		size += n
+	}
+	if len(current) > 0 {
+		messages = append(messages, strings.Join(current, "\n"))
+	}
+	return messages
+}
+
+// sendDigest posts weekly digest of the group to its mentors chat, or to
+// MENTORS_CHAT when the group has none
+func (b *Bot) sendDigest(group model.Group) error {
+	chat := group.MentorsChat
+	if chat == 0 {
+		chat = b.c.MentorsChat
+	}
+	if chat == 0 {
+		logrus.Warnf("No mentors chat for digest of group %d\n", group.ID)
+		return nil
+	}
+	messages, err := b.digest(group, b.now().In(b.location(group)))
+	if err != nil {
+		return err
+	}
+	for _, text := range messages {
+		message := tgbotapi.NewMessage(chat, text)
+		message.ParseMode = tgbotapi.ModeHTML
+		if _, err := b.tgAPI.Send(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
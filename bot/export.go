@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/maddevsio/punisher/export"
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// exportCommand handles "экспорт [@user] [с] [по] [формат]": replies with a
+// document holding standups and punishments of the group. Days are
+// YYYY-MM-DD in the group's timezone, the first one is since and the second
+// one is until, both inclusive.
+func (b *Bot) exportCommand(channel int64, args []string) {
+	loc := b.location(b.groupSettings(channel))
+	opts := export.Options{GroupID: channel, Format: export.CSV, Location: loc, Labels: &export.Labels{
+		Title:      b.t(channel, msgExportTitle, nil),
+		Yesterday:  b.t(channel, msgExportYesterday, nil),
+		Today:      b.t(channel, msgExportToday, nil),
+		Blockers:   b.t(channel, msgExportBlockers, nil),
+		Standup:    b.t(channel, msgExportStandup, nil),
+		Punishment: b.t(channel, msgExportPunishment, nil),
+		Done:       b.t(channel, msgExportDone, nil),
+	}}
+	days := 0
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "@"):
+			opts.Username = strings.TrimPrefix(arg, "@")
+		case contains(export.Formats, strings.ToLower(arg)):
+			opts.Format = strings.ToLower(arg)
+		default:
+			day, err := export.ParseDay(arg, loc)
+			if err != nil || days == 2 {
+				b.say(channel, msgExportFormat, i18n.Vars{"formats": strings.Join(export.Formats, "|")})
+				return
+			}
+			if days == 0 {
+				opts.Since = day
+			} else {
+				opts.Until = day.AddDate(0, 0, 1)
+			}
+			days++
+		}
+	}
+	var buf bytes.Buffer
+	if err := export.Write(&buf, b.db, opts); err != nil {
+		logrus.Errorf("export failed: %v\n", err)
+		b.say(channel, msgExportFailed, nil)
+		return
+	}
+	name := "standups"
+	if opts.Username != "" {
+		name += "-" + opts.Username
+	}
+	document := tgbotapi.NewDocumentUpload(channel, tgbotapi.FileBytes{Name: name + "." + opts.Format, Bytes: buf.Bytes()})
+	if _, err := b.tgAPI.Send(document); err != nil {
+		logrus.Errorf("sending export failed: %v\n", err)
+	}
+}
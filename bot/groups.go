@@ -0,0 +1,382 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maddevsio/punisher/calendar"
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/model"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// groupSetting parses value of "@bot настройки <key> <value>" into group
+type groupSetting func(b *Bot, g *model.Group, value string) error
+
+var groupSettingParsers = map[string]groupSetting{
+	"время": func(b *Bot, g *model.Group, value string) error {
+		t, err := time.Parse("15:04", value)
+		if err != nil {
+			return b.settingError(g, msgSettingTime, nil)
+		}
+		g.PunishTime = t.Format("15:04")
+		return nil
+	},
+	"наказание": func(b *Bot, g *model.Group, value string) error {
+		if _, ok := b.punishments.Get(value); !ok && value != randomPunishment {
+			names := append(b.punishments.Names(), randomPunishment)
+			return b.settingError(g, msgSettingPunishment, i18n.Vars{"names": strings.Join(names, ", ")})
+		}
+		g.PunishmentType = value
+		return nil
+	},
+	"уведомлять": func(b *Bot, g *model.Group, value string) error {
+		switch value {
+		case "да":
+			g.NotifyMentors = flag(true)
+		case "нет":
+			g.NotifyMentors = flag(false)
+		default:
+			return b.settingError(g, msgSettingNotify, nil)
+		}
+		return nil
+	},
+	"менторы": func(b *Bot, g *model.Group, value string) error {
+		chat, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return b.settingError(g, msgSettingMentors, nil)
+		}
+		g.MentorsChat = chat
+		return nil
+	},
+	"пояс": func(b *Bot, g *model.Group, value string) error {
+		if _, err := time.LoadLocation(value); err != nil {
+			return b.settingError(g, msgSettingTimezone, i18n.Vars{"value": value})
+		}
+		g.Timezone = value
+		return nil
+	},
+	"дни": func(b *Bot, g *model.Group, value string) error {
+		days, err := calendar.ParseWeekdays(value)
+		if err != nil {
+			return b.settingError(g, msgSettingWorkdays, nil)
+		}
+		g.Workdays = calendar.FormatWeekdays(days)
+		return nil
+	},
+	"напоминать": func(b *Bot, g *model.Group, value string) error {
+		minutes, err := parseReminders(value)
+		if err != nil {
+			return b.settingError(g, msgSettingReminders, nil)
+		}
+		g.Reminders = formatReminders(minutes)
+		return nil
+	},
+	"лично": func(b *Bot, g *model.Group, value string) error {
+		switch value {
+		case "да":
+			g.RemindPrivately = flag(true)
+		case "нет":
+			g.RemindPrivately = flag(false)
+		default:
+			return b.settingError(g, msgSettingPrivately, nil)
+		}
+		return nil
+	},
+	"бонус": func(b *Bot, g *model.Group, value string) error {
+		if value == "нет" {
+			g.StreakBonus = -1
+			return nil
+		}
+		streak, err := strconv.Atoi(value)
+		if err != nil || streak < 1 {
+			return b.settingError(g, msgSettingBonus, nil)
+		}
+		g.StreakBonus = streak
+		return nil
+	},
+	"максимум": func(b *Bot, g *model.Group, value string) error {
+		lives, err := strconv.Atoi(value)
+		if err != nil || lives < 1 {
+			return b.settingError(g, msgSettingMaxLives, nil)
+		}
+		g.MaxLives = lives
+		return nil
+	},
+	"языки": func(b *Bot, g *model.Group, value string) error {
+		codes, err := b.languages.ParseCodes(value)
+		if err != nil {
+			return b.settingError(g, msgSettingLanguages, i18n.Vars{"codes": strings.Join(b.languages.Codes(), ", ")})
+		}
+		g.Languages = strings.Join(codes, ",")
+		return nil
+	},
+	"локаль": func(b *Bot, g *model.Group, value string) error {
+		if !b.catalog.HasLocale(value) {
+			return b.settingError(g, msgSettingLocale, i18n.Vars{"value": value, "locales": strings.Join(b.catalog.Locales(), ", ")})
+		}
+		g.Locale = value
+		return nil
+	},
+	"общий": func(b *Bot, g *model.Group, value string) error {
+		switch value {
+		case "да":
+			g.SharedStandups = flag(true)
+		case "нет":
+			g.SharedStandups = flag(false)
+		default:
+			return b.settingError(g, msgSettingShared, nil)
+		}
+		return nil
+	},
+	"жизни": func(b *Bot, g *model.Group, value string) error {
+		lives, err := strconv.Atoi(value)
+		if err != nil || lives < 1 {
+			return b.settingError(g, msgSettingLives, nil)
+		}
+		g.Lives = lives
+		return nil
+	},
+}
+
+// settingError is the reply to a wrong value of the group's setting
+func (b *Bot) settingError(g *model.Group, key string, vars i18n.Vars) error {
+	return errors.New(b.t(g.ID, key, vars))
+}
+
+// groupSettings returns settings of the group, those its admins never
+// changed are global defaults from config
+func (b *Bot) groupSettings(groupID int64) model.Group {
+	return b.withDefaults(b.storedGroup(groupID))
+}
+
+// storedGroup returns settings admins of the group changed, a group that
+// never changed them has only ID
+func (b *Bot) storedGroup(groupID int64) model.Group {
+	group, err := b.db.SelectGroup(groupID)
+	if err == nil {
+		return group
+	}
+	if err != sql.ErrNoRows {
+		logrus.Errorf("SelectGroup failed: %v\n", err)
+	}
+	return model.Group{ID: groupID}
+}
+
+// withDefaults fills settings the group did not change from config
+func (b *Bot) withDefaults(group model.Group) model.Group {
+	if group.PunishTime == "" {
+		group.PunishTime = b.c.PunishTime
+	}
+	if group.PunishmentType == "" {
+		group.PunishmentType = b.c.PunishmentType
+	}
+	if group.NotifyMentors == nil {
+		group.NotifyMentors = flag(b.c.NotifyMentors)
+	}
+	if group.MentorsChat == 0 {
+		group.MentorsChat = b.c.MentorsChat
+	}
+	if group.Lives == 0 {
+		group.Lives = b.c.Lives
+	}
+	if group.Timezone == "" {
+		group.Timezone = b.c.Timezone
+	}
+	if group.Workdays == "" {
+		group.Workdays = b.c.Workdays
+	}
+	if group.Reminders == "" {
+		group.Reminders = b.c.Reminders
+	}
+	if group.RemindPrivately == nil {
+		group.RemindPrivately = flag(b.c.RemindPrivately)
+	}
+	if group.StreakBonus == 0 {
+		group.StreakBonus = b.c.StreakBonus
+	}
+	if group.MaxLives == 0 {
+		group.MaxLives = b.c.MaxLives
+	}
+	if group.Languages == "" {
+		group.Languages = b.c.Languages
+	}
+	if group.Locale == "" {
+		group.Locale = b.c.Locale
+	}
+	if group.SharedStandups == nil {
+		group.SharedStandups = flag(b.c.SharedStandups)
+	}
+	return group
+}
+
+// flag returns a group setting set to v
+func flag(v bool) *bool {
+	return &v
+}
+
+// enabled tells if a group setting is set and on
+func enabled(v *bool) bool {
+	return v != nil && *v
+}
+
+// location returns timezone of the group. Timezones are validated when
+// they are set, so a failure here means the zone database is broken.
+func (b *Bot) location(group model.Group) *time.Location {
+	loc, err := time.LoadLocation(group.Timezone)
+	if err != nil {
+		logrus.Errorf("LoadLocation(%q) failed: %v\n", group.Timezone, err)
+		return b.loc
+	}
+	return loc
+}
+
+// saveGroupSettings stores settings the group changed, creating its row on
+// first change
+func (b *Bot) saveGroupSettings(group model.Group) (model.Group, error) {
+	_, err := b.db.SelectGroup(group.ID)
+	if err == sql.ErrNoRows {
+		return b.db.CreateGroup(group)
+	}
+	if err != nil {
+		return group, err
+	}
+	return b.db.UpdateGroup(group)
+}
+
+// groupSettingsCommand shows group settings or, for admins, changes one of them
+func (b *Bot) groupSettingsCommand(channel int64, args []string, isAdmin bool) {
+	group := b.storedGroup(channel)
+	if len(args) == 0 {
+		b.tgAPI.Send(tgbotapi.NewMessage(channel, b.formatGroupSettings(b.withDefaults(group))))
+		return
+	}
+	if !isAdmin {
+		b.say(channel, msgSettingsAdminsOnly, nil)
+		return
+	}
+	setting, ok := groupSettingParsers[args[0]]
+	if !ok || len(args) < 2 {
+		keys := []string{}
+		for key := range groupSettingParsers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b.say(channel, msgSettingsFormat, i18n.Vars{"keys": strings.Join(keys, "|")})
+		return
+	}
+	if err := setting(b, &group, strings.Join(args[1:], " ")); err != nil {
+		b.tgAPI.Send(tgbotapi.NewMessage(channel, err.Error()))
+		return
+	}
+	group, err := b.saveGroupSettings(group)
+	if err != nil {
+		logrus.Errorf("saveGroupSettings failed: %v\n", err)
+		b.say(channel, msgSettingsNotSaved, nil)
+		return
+	}
+	b.tgAPI.Send(tgbotapi.NewMessage(channel, b.formatGroupSettings(b.withDefaults(group))))
+}
+
+// formatGroupSettings lists settings in the group's locale, values are the
+// ones to type into "настройки", e.g. "да" and "нет"
+func (b *Bot) formatGroupSettings(g model.Group) string {
+	bonus := "нет"
+	if g.StreakBonus > 0 {
+		bonus = strconv.Itoa(g.StreakBonus)
+	}
+	return b.t(g.ID, msgSettings, i18n.Vars{
+		"time":       g.PunishTime,
+		"timezone":   g.Timezone,
+		"workdays":   g.Workdays,
+		"reminders":  g.Reminders,
+		"privately":  yesNo(enabled(g.RemindPrivately)),
+		"punishment": g.PunishmentType,
+		"notify":     yesNo(enabled(g.NotifyMentors)),
+		"mentors":    g.MentorsChat,
+		"lives":      g.Lives,
+		"bonus":      bonus,
+		"maxLives":   g.MaxLives,
+		"languages":  g.Languages,
+		"locale":     g.Locale,
+		"shared":     yesNo(enabled(g.SharedStandups)),
+	})
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "да"
+	}
+	return "нет"
+}
+
+// scheduleChecks runs every minute, reminds interns of every group whose
+// reminder time has come, sends weekly digests and starts daily check of
+// every group whose deadline has come in the group's timezone. Ticks drift
+// and may skip a minute, so a moment counts as come once it is passed since
+// the previous tick. Moments passed before the bot started are not caught
+// up, a restart must not punish anybody twice.
+func (b *Bot) scheduleChecks() {
+	b.touch(&b.lastTick)
+	tick := b.now()
+	prev := b.prevTick
+	if prev.IsZero() || prev.After(tick) {
+		prev = tick.Add(-time.Minute)
+	}
+	b.prevTick = tick
+	passed := func(at time.Time) bool {
+		return at.After(prev) && !at.After(tick)
+	}
+	groups, err := b.db.ListGroups()
+	if err != nil {
+		logrus.Errorf("ListGroups failed: %v\n", err)
+		return
+	}
+	for _, id := range groups {
+		group := b.groupSettings(id)
+		now := tick.In(b.location(group))
+		today := now.Format("2006-01-02")
+		minutes, _ := parseReminders(group.Reminders)
+		for _, m := range minutes {
+			// a reminder before a deadline just after midnight is due the day before
+			for _, day := range []time.Time{now, now.AddDate(0, 0, 1)} {
+				deadline, err := clock(day, group.PunishTime)
+				key := fmt.Sprintf("%d/%d", id, m)
+				if err != nil || !passed(deadline.Add(-time.Duration(m)*time.Minute)) || b.lastReminder[key] == deadline.Format("2006-01-02") {
+					continue
+				}
+				b.lastReminder[key] = deadline.Format("2006-01-02")
+				if err := b.remind(group, m, deadline); err != nil && err != errDayOff {
+					logrus.Errorf("remind failed: %v\n", err)
+				}
+			}
+		}
+		if at, err := clock(now, b.digestAt); err == nil && now.Weekday() == b.digestDay && passed(at) && b.lastDigest[id] != today {
+			b.lastDigest[id] = today
+			if err := b.sendDigest(group); err != nil {
+				logrus.Errorf("sendDigest failed: %v\n", err)
+			}
+		}
+		deadline, err := clock(now, group.PunishTime)
+		if err != nil || !passed(deadline) || b.lastCheck[id] == today {
+			continue
+		}
+		b.lastCheck[id] = today
+		b.dailyJob(group)
+	}
+}
+
+// clock returns "15:04" time of the day in the day's location
+func clock(day time.Time, hhmm string) (time.Time, error) {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), nil
+}
@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// staleAfter is how long polling and scheduler may stay silent, getUpdates
+// holds a request for telegramAPIUpdateInterval seconds and scheduleChecks
+// runs every minute
+const staleAfter = 3 * time.Minute
+
+// pollRetry is the pause after a failed getUpdates
+const pollRetry = 3 * time.Second
+
+// poll gets updates from Telegram into b.updates, like
+// tgbotapi.GetUpdatesChan does, and remembers when it last succeeded
+func (b *Bot) poll(config tgbotapi.UpdateConfig) {
+	for {
+		updates, err := b.tgAPI.GetUpdates(config)
+		if err != nil {
+			logrus.Errorf("GetUpdates failed, retrying in %v: %v\n", pollRetry, err)
+			time.Sleep(pollRetry)
+			continue
+		}
+		b.touch(&b.lastPoll)
+		for _, update := range updates {
+			if update.UpdateID >= config.Offset {
+				config.Offset = update.UpdateID + 1
+				b.updates <- update
+			}
+		}
+	}
+}
+
+// CheckPolling fails when Telegram was not polled successfully for too long
+func (b *Bot) CheckPolling() error {
+	return b.fresh("telegram poll", &b.lastPoll)
+}
+
+// CheckScheduler fails when scheduled checks stopped running or Start was
+// not called
+func (b *Bot) CheckScheduler() error {
+	return b.fresh("scheduler tick", &b.lastTick)
+}
+
+// touch stores current time to t
+func (b *Bot) touch(t *int64) {
+	atomic.StoreInt64(t, b.now().UnixNano())
+}
+
+// fresh fails when t was not touched during staleAfter
+func (b *Bot) fresh(what string, t *int64) error {
+	last := atomic.LoadInt64(t)
+	if last == 0 {
+		return fmt.Errorf("no %s yet", what)
+	}
+	if age := b.now().Sub(time.Unix(0, last)); age > staleAfter {
+		return fmt.Errorf("last %s was %v ago", what, age.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package bot
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/model"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// callbackPrefix starts data of mentors' inline buttons: "punishment:<action>:<id>"
+const callbackPrefix = "punishment"
+
+const (
+	confirmAction = "confirm"
+	rejectAction  = "reject"
+)
+
+// handleProof accepts a photo or video proving that punishment is done.
+// Intern either replies to the bot's punishment message or mentions the bot
+// in the caption, then the oldest unfinished punishment is meant. Replies to
+// other messages of the bot are not proofs.
+// Captions that are standups are not proofs, they are left for standup
+// handling. It returns false when message is not a proof.
+func (b *Bot) handleProof(message *tgbotapi.Message) bool {
+	if (message.Photo == nil && message.Video == nil) || message.From == nil || message.Chat == nil {
+		return false
+	}
+	channel := message.Chat.ID
+	intern, err := b.db.FindInternByUserID(int64(message.From.ID), channel)
+	if err != nil {
+		return false
+	}
+	var record model.Punishment
+	switch reply := message.ReplyToMessage; {
+	case reply != nil && reply.From != nil && reply.From.ID == b.self.ID:
+		record, err = b.db.FindPunishmentByMessage(channel, reply.MessageID)
+		if err == sql.ErrNoRows {
+			// a reply to another message of the bot
+			return false
+		}
+	case strings.Contains(message.Caption, "@"+b.self.UserName):
+		if b.isStandup(message) {
+			return false
+		}
+		record, err = b.oldestUnfinished(intern)
+	default:
+		return false
+	}
+	if err == sql.ErrNoRows {
+		b.say(channel, msgProofUnknown, i18n.Vars{"user": intern.Username})
+		return true
+	}
+	if err != nil {
+		logrus.Errorf("looking for punishment failed: %v\n", err)
+		return true
+	}
+	if record.InternID != intern.ID {
+		b.say(channel, msgProofNotYours, i18n.Vars{"user": intern.Username})
+		return true
+	}
+	switch record.Status {
+	case model.PunishmentReview:
+		b.say(channel, msgProofInReview, i18n.Vars{"user": intern.Username})
+		return true
+	case model.PunishmentDone:
+		b.say(channel, msgProofDone, i18n.Vars{"user": intern.Username})
+		return true
+	}
+	record.Status = model.PunishmentReview
+	record.ProofMessageID = message.MessageID
+	if _, err := b.db.UpdatePunishment(record); err != nil {
+		logrus.Errorf("UpdatePunishment failed: %v\n", err)
+		return true
+	}
+	b.askMentors(record, intern)
+	b.say(channel, msgProofSent, i18n.Vars{"user": intern.Username})
+	return true
+}
+
+// oldestUnfinished returns the oldest pending punishment of intern
+func (b *Bot) oldestUnfinished(intern model.Intern) (model.Punishment, error) {
+	punishments, err := b.db.ListPunishments(intern.GroupID)
+	if err != nil {
+		return model.Punishment{}, err
+	}
+	for _, p := range punishments {
+		if p.InternID == intern.ID && p.Status == model.PunishmentPending {
+			return p, nil
+		}
+	}
+	return model.Punishment{}, sql.ErrNoRows
+}
+
+// askMentors posts proof with confirm and reject buttons to the mentors
+// chat of the group or to the group itself when it has none
+func (b *Bot) askMentors(record model.Punishment, intern model.Intern) {
+	chat := b.groupSettings(record.GroupID).MentorsChat
+	message := tgbotapi.NewMessage(chat, b.t(record.GroupID, msgProofAsk, i18n.Vars{"user": intern.Username, "punishment": b.describePunishment(record)}))
+	if chat == 0 {
+		message.ChatID = record.GroupID
+		message.ReplyToMessageID = record.ProofMessageID
+	} else {
+		forward := tgbotapi.NewForward(chat, record.GroupID, record.ProofMessageID)
+		if _, err := b.tgAPI.Send(forward); err != nil {
+			logrus.Errorf("forwarding proof failed: %v\n", err)
+		}
+	}
+	id := strconv.FormatInt(record.ID, 10)
+	message.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(record.GroupID, msgProofConfirm, nil), strings.Join([]string{callbackPrefix, confirmAction, id}, ":")),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(record.GroupID, msgProofReject, nil), strings.Join([]string{callbackPrefix, rejectAction, id}, ":")),
+	))
+	if _, err := b.tgAPI.Send(message); err != nil {
+		logrus.Errorf("asking mentors failed: %v\n", err)
+	}
+}
+
+// handleCallback handles mentors pressing inline buttons under a proof
+func (b *Bot) handleCallback(query *tgbotapi.CallbackQuery) {
+	parts := strings.Split(query.Data, ":")
+	if len(parts) != 3 || parts[0] != callbackPrefix {
+		return
+	}
+	// answers are in the locale of the chat with the buttons until the
+	// punishment, and so its group, is known
+	var groupID int64
+	if query.Message != nil && query.Message.Chat != nil {
+		groupID = query.Message.Chat.ID
+	}
+	answer := func(key string) {
+		if _, err := b.tgAPI.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, b.t(groupID, key, nil))); err != nil {
+			logrus.Errorf("AnswerCallbackQuery failed: %v\n", err)
+		}
+	}
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return
+	}
+	record, err := b.db.SelectPunishment(id)
+	if err != nil {
+		logrus.Errorf("SelectPunishment failed: %v\n", err)
+		answer(msgProofMissing)
+		return
+	}
+	groupID = record.GroupID
+	isAdmin, err := b.senderIsAdminInChannel(query.From, record.GroupID)
+	if err != nil {
+		logrus.Errorf("senderIsAdminInChannel func failed: [%v]\n", err)
+	}
+	if !isAdmin {
+		answer(msgProofAdminsOnly)
+		return
+	}
+	if record.Status != model.PunishmentReview {
+		answer(msgProofChecked)
+		return
+	}
+	intern, err := b.db.SelectIntern(record.InternID)
+	if err != nil {
+		logrus.Errorf("SelectIntern failed: %v\n", err)
+		return
+	}
+	var verdict, text string
+	switch parts[1] {
+	case confirmAction:
+		record.Status = model.PunishmentDone
+		record.Done = record.Amount
+		verdict, text = msgProofConfirmed, msgPunishmentConfirmed
+	case rejectAction:
+		record.Status = model.PunishmentPending
+		record.ProofMessageID = 0
+		verdict, text = msgProofRejected, msgPunishmentRejected
+	default:
+		return
+	}
+	if _, err := b.db.UpdatePunishment(record); err != nil {
+		logrus.Errorf("UpdatePunishment failed: %v\n", err)
+		answer(msgSaveFailed)
+		return
+	}
+	answer(verdict)
+	if query.Message != nil && query.Message.Chat != nil {
+		// editing without markup removes the buttons
+		edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID,
+			fmt.Sprintf("%s\n%s (@%s)", query.Message.Text, b.t(groupID, verdict, nil), query.From.UserName))
+		if _, err := b.tgAPI.Send(edit); err != nil {
+			logrus.Errorf("editing mentors message failed: %v\n", err)
+		}
+	}
+	b.say(record.GroupID, text, i18n.Vars{"user": intern.Username, "punishment": b.describePunishment(record)})
+}
+
+// describePunishment tells what intern has to do, e.g. "73 отжимания"
+func (b *Bot) describePunishment(record model.Punishment) string {
+	p, _ := b.punishments.Get(record.Type)
+	switch p := p.(type) {
+	case Exercise:
+		return b.amount(record.GroupID, p, record.Amount)
+	case Task:
+		return p.describe(b, record.GroupID)
+	case poetry:
+		return b.t(record.GroupID, msgPunishmentReadPoem, nil)
+	}
+	return record.Type
+}
+
+// unfinishedListed is how many unfinished punishments are listed, older
+// ones are only counted so the list fits into a message
+const unfinishedListed = 10
+
+// unfinishedPunishments lists the latest punishments of the group that are
+// not done yet
+func (b *Bot) unfinishedPunishments(groupID int64) ([]string, error) {
+	punishments, err := b.db.ListPunishments(groupID)
+	if err != nil {
+		return nil, err
+	}
+	interns, err := b.db.ListGroupInterns(groupID)
+	if err != nil {
+		return nil, err
+	}
+	usernames := map[int64]string{}
+	for _, intern := range interns {
+		usernames[intern.ID] = intern.Username
+	}
+	loc := b.location(b.groupSettings(groupID))
+	lines := []string{}
+	for _, p := range punishments {
+		username, ok := usernames[p.InternID]
+		if p.Status == model.PunishmentDone || !ok {
+			// done or intern was removed
+			continue
+		}
+		lines = append(lines, b.t(groupID, msgUnfinishedLine, i18n.Vars{
+			"user":       username,
+			"punishment": b.describePunishment(p),
+			"date":       p.Issued.In(loc).Format("2006-01-02"),
+			"done":       p.Done,
+			"review":     p.Status == model.PunishmentReview,
+		}))
+	}
+	if older := len(lines) - unfinishedListed; older > 0 {
+		lines = append(lines[older:], b.t(groupID, msgUnfinishedOlder, i18n.Vars{"n": older}))
+	}
+	return lines, nil
+}
+
+// listPunishments posts unfinished punishments of the group
+func (b *Bot) listPunishments(channel int64) {
+	lines, err := b.unfinishedPunishments(channel)
+	if err != nil {
+		logrus.Errorf("unfinishedPunishments failed: %v\n", err)
+		return
+	}
+	if len(lines) == 0 {
+		b.say(channel, msgUnfinishedNone, nil)
+		return
+	}
+	b.tgAPI.Send(tgbotapi.NewMessage(channel, b.t(channel, msgUnfinished, nil)+"\n"+strings.Join(lines, "\n")))
+}
@@ -0,0 +1,600 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/model"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// Keys of bot messages. Exercises also have "punishment.<name>" and
+// "unit.<name>" messages, tasks may have "task.<name>" ones.
+const (
+	msgStandupAccepted       = "standup.accepted"
+	msgStandupNotSaved       = "standup.not_saved"
+	msgStandupEdited         = "standup.edited"
+	msgStandupMissing        = "standup.missing"
+	msgStandupTemplate       = "standup.template"
+	msgSectionYesterday      = "section.yesterday"
+	msgSectionToday          = "section.today"
+	msgSectionBlockers       = "section.blockers"
+	msgLivesLeft             = "lives.left"
+	msgLivesKicked           = "lives.kicked"
+	msgPunishmentTask        = "punishment.task"
+	msgPunishmentPoetry      = "punishment.poetry"
+	msgStreakBonus           = "streak.bonus"
+	msgCheckDone             = "check.done"
+	msgCheckDayOff           = "check.day_off"
+	msgCheckExcused          = "check.excused"
+	msgCheckUnfinished       = "check.unfinished"
+	msgReminderGroup         = "reminder.group"
+	msgReminderPrivate       = "reminder.private"
+	msgDigestTitle           = "digest.title"
+	msgDigestIntern          = "digest.intern"
+	msgDigestBlockers        = "digest.blockers"
+	msgAdminsOnly            = "admins.only"
+	msgDateFormat            = "date.format"
+	msgSaveFailed            = "save.failed"
+	msgInternFormat          = "intern.format"
+	msgInternAdded           = "intern.added"
+	msgInternNotAdded        = "intern.not_added"
+	msgInternAlready         = "intern.already"
+	msgInternUnknown         = "intern.unknown"
+	msgInternNotRemoved      = "intern.not_removed"
+	msgInternRemoved         = "intern.removed"
+	msgSettings              = "settings.show"
+	msgSettingsFormat        = "settings.format"
+	msgSettingsAdminsOnly    = "settings.admins_only"
+	msgSettingsNotSaved      = "settings.not_saved"
+	msgSettingTime           = "setting.time"
+	msgSettingPunishment     = "setting.punishment"
+	msgSettingNotify         = "setting.notify"
+	msgSettingMentors        = "setting.mentors"
+	msgSettingTimezone       = "setting.timezone"
+	msgSettingWorkdays       = "setting.workdays"
+	msgSettingReminders      = "setting.reminders"
+	msgSettingPrivately      = "setting.privately"
+	msgSettingBonus          = "setting.bonus"
+	msgSettingMaxLives       = "setting.max_lives"
+	msgSettingLanguages      = "setting.languages"
+	msgSettingLocale         = "setting.locale"
+	msgSettingShared         = "setting.shared"
+	msgSettingLives          = "setting.lives"
+	msgDayOffWeekend         = "dayoff.reason"
+	msgDayOffFormat          = "dayoff.format"
+	msgDayOffAlready         = "dayoff.already"
+	msgDayOffRemoved         = "dayoff.removed"
+	msgDayOffNotOff          = "dayoff.not_off"
+	msgDayOffAdded           = "dayoff.added"
+	msgDaysOff               = "dayoff.list"
+	msgDaysOffNone           = "dayoff.none"
+	msgDaysOffAbsent         = "dayoff.absent"
+	msgAbsenceVacationFormat = "absence.format_vacation"
+	msgAbsenceSickFormat     = "absence.format_sick"
+	msgAbsenceReturnedFormat = "absence.format_returned"
+	msgAbsenceOrder          = "absence.order"
+	msgAbsenceUnknown        = "absence.unknown"
+	msgAbsenceNotSaved       = "absence.not_saved"
+	msgAbsenceSaved          = "absence.saved"
+	msgAbsenceNotAway        = "absence.not_away"
+	msgAbsenceReturned       = "absence.returned"
+	msgAbsenceVacation       = "absence.vacation"
+	msgAbsenceSick           = "absence.sick"
+	msgDebtNotIntern         = "debt.not_intern"
+	msgDebtNothingOwed       = "debt.nothing_owed"
+	msgDebtCounted           = "debt.counted"
+	msgDebtStranger          = "debt.stranger"
+	msgDebtNoneOwn           = "debt.none_own"
+	msgDebtOwn               = "debt.own"
+	msgDebtNone              = "debt.none"
+	msgDebts                 = "debt.list"
+	msgExportFormat          = "export.format"
+	msgExportFailed          = "export.failed"
+	msgExportTitle           = "export.title"
+	msgExportYesterday       = "export.yesterday"
+	msgExportToday           = "export.today"
+	msgExportBlockers        = "export.blockers"
+	msgExportStandup         = "export.standup"
+	msgExportPunishment      = "export.punishment"
+	msgExportDone            = "export.done"
+	msgProofUnknown          = "proof.unknown"
+	msgProofNotYours         = "proof.not_yours"
+	msgProofInReview         = "proof.in_review"
+	msgProofDone             = "proof.done"
+	msgProofSent             = "proof.sent"
+	msgProofAsk              = "proof.ask"
+	msgProofConfirm          = "proof.confirm"
+	msgProofReject           = "proof.reject"
+	msgProofMissing          = "proof.missing"
+	msgProofAdminsOnly       = "proof.admins_only"
+	msgProofChecked          = "proof.checked"
+	msgProofConfirmed        = "proof.confirmed"
+	msgProofRejected         = "proof.rejected"
+	msgPunishmentConfirmed   = "punishment.confirmed"
+	msgPunishmentRejected    = "punishment.rejected"
+	msgPunishmentReadPoem    = "punishment.read_poem"
+	msgUnfinished            = "unfinished.list"
+	msgUnfinishedNone        = "unfinished.none"
+	msgUnfinishedLine        = "unfinished.line"
+	msgUnfinishedOlder       = "unfinished.older"
+	msgRatingEmpty           = "rating.empty"
+	msgRating                = "rating.list"
+	msgRatingLine            = "rating.line"
+	msgTexts                 = "text.list"
+	msgTextChanged           = "text.changed"
+	msgTextUnknown           = "text.unknown"
+	msgTextAdminsOnly        = "text.admins_only"
+	msgTextReset             = "text.reset"
+	msgTextInvalid           = "text.invalid"
+	msgTextNotSaved          = "text.not_saved"
+	msgTextSaved             = "text.saved"
+)
+
+var messagesRU = map[string]string{
+	msgStandupAccepted:       `@{{.user}} спасибо. Я принял твой стендап`,
+	msgStandupNotSaved:       `@{{.user}} у тебя кажется норм стендап, но сохранять его не буду.`,
+	msgStandupEdited:         `@{{.user}} спасибо. исправления приняты.`,
+	msgStandupMissing:        `@{{.user}}, не принял стендап: не нашел {{.missing}}. Как писать стендап, покажет @{{.bot}} шаблон`,
+	msgStandupTemplate:       "Стендап пишется так:\n@{{.bot}}\nВчера: что сделал\nСегодня: что собираюсь сделать\nПроблемы: что мешает, или нет",
+	msgSectionYesterday:      `что сделано вчера`,
+	msgSectionToday:          `планов на сегодня`,
+	msgSectionBlockers:       `проблем`,
+	msgLivesLeft:             `@{{.user}} осталось {{plural .lives "жизнь" "жизни" "жизней"}}`,
+	msgLivesKicked:           `У @{{.user}} не осталось жизней. Удаляю.`,
+	msgPunishmentTask:        `@{{.user}} в наказание за пропущенный стэндап {{.task}}`,
+	msgPunishmentPoetry:      `@{{.user}} в наказание за пропущенный стэндап прочитай этот стих на весь офис: {{.link}}`,
+	"punishment.pushups":     `@{{.user}} в наказание за пропущенный стэндап тебе {{plural .n "отжимание" "отжимания" "отжиманий"}}`,
+	"punishment.snowflakes":  `@{{.user}}, в наказание за пропущенный стэндап c тебя {{plural .n "снежинка" "снежинки" "снежинок"}}!`,
+	"punishment.situps":      `@{{.user}} в наказание за пропущенный стэндап тебе {{plural .n "приседание" "приседания" "приседаний"}}`,
+	"unit.pushups":           `{{plural .n "отжимание" "отжимания" "отжиманий"}}`,
+	"unit.snowflakes":        `{{plural .n "снежинка" "снежинки" "снежинок"}}`,
+	"unit.situps":            `{{plural .n "приседание" "приседания" "приседаний"}}`,
+	msgStreakBonus:           `@{{.user}}, {{plural .streak "стендап" "стендапа" "стендапов"}} подряд! Держи бонусную жизнь, теперь у тебя {{plural .lives "жизнь" "жизни" "жизней"}}`,
+	msgCheckDone:             `Каратель завершил свою работу ;)`,
+	msgCheckDayOff:           `Сегодня выходной ({{.reason}}), никого не наказываю. Отдыхайте!`,
+	msgCheckExcused:          `Не проверял:`,
+	msgCheckUnfinished:       `Еще не выполнено:`,
+	msgReminderGroup:         `{{.users}}, до дедлайна {{.minutes}} мин., а стендапа от вас еще нет!`,
+	msgReminderPrivate:       `Через {{.minutes}} мин. дедлайн, не забудь написать стендап в группу!`,
+	msgDigestTitle:           `<b>Итоги недели {{.since}} — {{.until}}</b>`,
+	msgDigestIntern:          `@{{.user}}: стендапы {{.submitted}} из {{.expected}}, наказания: {{.punishments}}, {{plural .lives "жизнь" "жизни" "жизней"}}, в среднем в {{.average}}`,
+	msgDigestBlockers:        `    <i>проблемы:</i> {{.blockers}}`,
+	msgAdminsOnly:            `Это могут только админы группы`,
+	msgDateFormat:            `Дату нужно указать как ГГГГ-ММ-ДД`,
+	msgSaveFailed:            `Не смог сохранить`,
+	msgInternFormat:          `Формат: {{.command}} @user`,
+	msgInternAdded:           `@{{.user}}, я слежу за тобой.`,
+	msgInternNotAdded:        `не буду следить за @{{.user}}`,
+	msgInternAlready:         `Уже слежу за @{{.user}}, зачем 2 раза просить?`,
+	msgInternUnknown:         `да я и не следил за @{{.user}}, а надо было?`,
+	msgInternNotRemoved:      `мне @{{.user}} очень нравится... Дальше послежу!`,
+	msgInternRemoved:         `@{{.user}}, я больше не слежу за тобой.`,
+	msgSettings:              "Настройки группы:\nвремя: {{.time}}\nпояс: {{.timezone}}\nдни: {{.workdays}}\nнапоминать: {{.reminders}}\nлично: {{.privately}}\nнаказание: {{.punishment}}\nуведомлять: {{.notify}}\nменторы: {{.mentors}}\nжизни: {{.lives}}\nбонус: {{.bonus}}\nмаксимум: {{.maxLives}}\nязыки: {{.languages}}\nлокаль: {{.locale}}\nобщий: {{.shared}}",
+	msgSettingsFormat:        `Формат: настройки <{{.keys}}> <значение>`,
+	msgSettingsAdminsOnly:    `Менять настройки могут только админы группы`,
+	msgSettingsNotSaved:      `Не смог сохранить настройки`,
+	msgSettingTime:           `время нужно указать как ЧЧ:ММ`,
+	msgSettingPunishment:     `не знаю такого наказания, выбери одно из: {{.names}}`,
+	msgSettingNotify:         `уведомлять можно только да или нет`,
+	msgSettingMentors:        `чат менторов нужно указать числом`,
+	msgSettingTimezone:       `не знаю часового пояса {{.value}}, нужно имя вроде Asia/Bishkek`,
+	msgSettingWorkdays:       `рабочие дни нужно перечислить через запятую: пн,вт,ср,чт,пт`,
+	msgSettingReminders:      `напоминания нужно указать в минутах до дедлайна через запятую, например 60,15, или нет`,
+	msgSettingPrivately:      `лично можно только да или нет`,
+	msgSettingBonus:          `бонус нужно указать числом стендапов подряд или нет`,
+	msgSettingMaxLives:       `максимум жизней должен быть хотя бы 1`,
+	msgSettingLanguages:      `языки стендапов нужно перечислить через запятую, доступны: {{.codes}}`,
+	msgSettingLocale:         `не знаю локали {{.value}}, выбери одну из: {{.locales}}`,
+	msgSettingShared:         `общий можно только да или нет`,
+	msgSettingLives:          `жизней должно быть хотя бы 1`,
+	msgDayOffWeekend:         `выходной`,
+	msgDayOffFormat:          `Формат: {{.command}} ГГГГ-ММ-ДД`,
+	msgDayOffAlready:         `{{.date}} и так выходной`,
+	msgDayOffRemoved:         `{{.date}} снова рабочий день`,
+	msgDayOffNotOff:          `{{.date}} и не был выходным`,
+	msgDayOffAdded:           `Ок, {{.date}} никого не наказываю`,
+	msgDaysOff:               `Выходные:`,
+	msgDaysOffNone:           `Ближайший месяц без праздников. Рабочие дни: {{.workdays}}`,
+	msgDaysOffAbsent:         `Отсутствуют:`,
+	msgAbsenceVacationFormat: `Формат: отпуск @user ГГГГ-ММ-ДД ГГГГ-ММ-ДД`,
+	msgAbsenceSickFormat:     `Формат: болеет @user [ГГГГ-ММ-ДД]`,
+	msgAbsenceReturnedFormat: `Формат: вернулся @user`,
+	msgAbsenceOrder:          `Отсутствие не может закончиться раньше, чем началось`,
+	msgAbsenceUnknown:        `я и не слежу за @{{.user}}`,
+	msgAbsenceNotSaved:       `Не смог сохранить, придется делать стендапы`,
+	msgAbsenceSaved:          `Ок, {{.absence}}, не наказываю`,
+	msgAbsenceNotAway:        `@{{.user}} никуда и не уходил`,
+	msgAbsenceReturned:       `С возвращением, @{{.user}}! Жду стендап.`,
+	msgAbsenceVacation:       `@{{.user}} в отпуске {{if eq .since .until}}{{.since}}{{else}}с {{.since}} по {{.until}}{{end}}`,
+	msgAbsenceSick:           `@{{.user}} болеет {{if eq .since .until}}{{.since}}{{else}}с {{.since}} по {{.until}}{{end}}`,
+	msgDebtNotIntern:         `@{{.user}}, ты не стажер, тебе можно не отжиматься`,
+	msgDebtNothingOwed:       `@{{.user}}, {{.amount}} ты никому не должен`,
+	msgDebtCounted:           `@{{.user}}, засчитал {{.amount}}, осталось {{.left}}{{if .extra}}. Лишние {{.extra}} в запас не идут{{end}}`,
+	msgDebtStranger:          `@{{.user}}, ты не стажер, у тебя долгов нет`,
+	msgDebtNoneOwn:           `@{{.user}}, долгов нет`,
+	msgDebtOwn:               `@{{.user}}, твой долг: {{.debt}}`,
+	msgDebtNone:              `Долгов нет`,
+	msgDebts:                 `Долги:`,
+	msgExportFormat:          `Формат: экспорт [@user] [ГГГГ-ММ-ДД [ГГГГ-ММ-ДД]] [{{.formats}}]`,
+	msgExportFailed:          `Не смог выгрузить стендапы`,
+	msgExportTitle:           `Журнал стендапов`,
+	msgExportYesterday:       `Вчера`,
+	msgExportToday:           `Сегодня`,
+	msgExportBlockers:        `Проблемы`,
+	msgExportStandup:         `стендап`,
+	msgExportPunishment:      `наказание`,
+	msgExportDone:            `выполнено`,
+	msgProofUnknown:          `@{{.user}}, не нашел, за какое это наказание`,
+	msgProofNotYours:         `@{{.user}}, это наказание не твое`,
+	msgProofInReview:         `@{{.user}}, это наказание уже на проверке`,
+	msgProofDone:             `@{{.user}}, это наказание уже засчитано`,
+	msgProofSent:             `@{{.user}}, отправил менторам на проверку`,
+	msgProofAsk:              `@{{.user}} говорит, что выполнил наказание: {{.punishment}}. Засчитать?`,
+	msgProofConfirm:          `Засчитать`,
+	msgProofReject:           `Не засчитать`,
+	msgProofMissing:          `Не нашел такого наказания`,
+	msgProofAdminsOnly:       `Проверять наказания могут только админы группы`,
+	msgProofChecked:          `Уже проверено`,
+	msgProofConfirmed:        `засчитано`,
+	msgProofRejected:         `не засчитано`,
+	msgPunishmentConfirmed:   `@{{.user}}, наказание засчитано: {{.punishment}}`,
+	msgPunishmentRejected:    `@{{.user}}, наказание не засчитано: {{.punishment}}. Попробуй еще раз`,
+	msgPunishmentReadPoem:    `прочитать стих`,
+	msgUnfinished:            `Не выполнено:`,
+	msgUnfinishedNone:        `Все наказания выполнены`,
+	msgUnfinishedLine:        `@{{.user}} — {{.punishment}} с {{.date}}{{if .done}}, сделано {{.done}}{{end}}{{if .review}} (на проверке){{end}}`,
+	msgUnfinishedOlder:       `и еще {{.n}} раньше`,
+	msgRatingEmpty:           `Я пока ни за кем не слежу`,
+	msgRating:                `Рейтинг:`,
+	msgRatingLine:            `{{.place}}. @{{.user}} — подряд: {{.streak}}, вовремя: {{.rate}}, жизней: {{.lives}}`,
+	msgTexts:                 `Тексты:`,
+	msgTextChanged:           `{{.key}} (изменен)`,
+	msgTextUnknown:           `Не знаю текста {{.key}}`,
+	msgTextAdminsOnly:        `Менять тексты могут только админы группы`,
+	msgTextReset:             "Вернул текст по умолчанию:\n{{.text}}",
+	msgTextInvalid:           `Не понял шаблон: {{.error}}`,
+	msgTextNotSaved:          `Не смог сохранить текст`,
+	msgTextSaved:             "Ок, теперь {{.key}}:\n{{.text}}",
+}
+
+var messagesEN = map[string]string{
+	msgStandupAccepted:       `@{{.user}} thanks, I've accepted your standup`,
+	msgStandupNotSaved:       `@{{.user}} your standup looks fine, but I couldn't save it.`,
+	msgStandupEdited:         `@{{.user}} thanks, changes accepted.`,
+	msgStandupMissing:        `@{{.user}}, that's not a standup: no {{.missing}} found. send @{{.bot}} template to see the format`,
+	msgStandupTemplate:       "Post your standup like this:\n@{{.bot}}\nYesterday: what you did\nToday: what you plan to do\nBlockers: what stops you, or none",
+	msgSectionYesterday:      `yesterday section`,
+	msgSectionToday:          `today section`,
+	msgSectionBlockers:       `blockers section`,
+	msgLivesLeft:             `@{{.user}} has {{plural .lives "life" "lives"}} left`,
+	msgLivesKicked:           `@{{.user}} has no lives left. Removing.`,
+	msgPunishmentTask:        `@{{.user}}, as a punishment for the missed standup {{.task}}`,
+	msgPunishmentPoetry:      `@{{.user}}, as a punishment for the missed standup read this poem aloud to the whole office: {{.link}}`,
+	"punishment.pushups":     `@{{.user}}, as a punishment for the missed standup do {{plural .n "push-up" "push-ups"}}`,
+	"punishment.snowflakes":  `@{{.user}}, as a punishment for the missed standup make {{plural .n "snowflake" "snowflakes"}}!`,
+	"punishment.situps":      `@{{.user}}, as a punishment for the missed standup do {{plural .n "sit-up" "sit-ups"}}`,
+	"unit.pushups":           `{{plural .n "push-up" "push-ups"}}`,
+	"unit.snowflakes":        `{{plural .n "snowflake" "snowflakes"}}`,
+	"unit.situps":            `{{plural .n "sit-up" "sit-ups"}}`,
+	msgStreakBonus:           `@{{.user}}, {{plural .streak "standup" "standups"}} in a row! Here is a bonus life, now you have {{plural .lives "life" "lives"}}`,
+	msgCheckDone:             `The punisher has finished its job ;)`,
+	msgCheckDayOff:           `Today is a day off ({{.reason}}), nobody is punished. Have a rest!`,
+	msgCheckExcused:          `Not checked:`,
+	msgCheckUnfinished:       `Not done yet:`,
+	msgReminderGroup:         `{{.users}}, {{.minutes}} min. left until the deadline and there is no standup from you yet!`,
+	msgReminderPrivate:       `The deadline is in {{.minutes}} min., don't forget to post your standup to the group!`,
+	msgDigestTitle:           `<b>Week {{.since}} — {{.until}}</b>`,
+	msgDigestIntern:          `@{{.user}}: standups {{.submitted}} of {{.expected}}, punishments: {{.punishments}}, {{plural .lives "life" "lives"}}, on average at {{.average}}`,
+	msgDigestBlockers:        `    <i>blockers:</i> {{.blockers}}`,
+	msgAdminsOnly:            `Only group admins can do this`,
+	msgDateFormat:            `Dates look like YYYY-MM-DD`,
+	msgSaveFailed:            `Couldn't save it`,
+	msgInternFormat:          `Format: {{.command}} @user`,
+	msgInternAdded:           `@{{.user}}, I'm watching you.`,
+	msgInternNotAdded:        `I won't watch @{{.user}}`,
+	msgInternAlready:         `I'm already watching @{{.user}}, why ask twice?`,
+	msgInternUnknown:         `I wasn't watching @{{.user}}, should I have?`,
+	msgInternNotRemoved:      `I like @{{.user}} too much... I'll keep watching!`,
+	msgInternRemoved:         `@{{.user}}, I'm not watching you anymore.`,
+	msgSettings:              "Group settings, change them with @bot настройки <key> <value>:\nвремя (deadline): {{.time}}\nпояс (timezone): {{.timezone}}\nдни (working days): {{.workdays}}\nнапоминать (reminders): {{.reminders}}\nлично (remind privately): {{.privately}}\nнаказание (punishment): {{.punishment}}\nуведомлять (notify mentors): {{.notify}}\nменторы (mentors chat): {{.mentors}}\nжизни (lives): {{.lives}}\nбонус (streak bonus): {{.bonus}}\nмаксимум (max lives): {{.maxLives}}\nязыки (standup languages): {{.languages}}\nлокаль (locale): {{.locale}}\nобщий (shared standups): {{.shared}}",
+	msgSettingsFormat:        `Format: настройки <{{.keys}}> <value>`,
+	msgSettingsAdminsOnly:    `Only group admins can change settings`,
+	msgSettingsNotSaved:      `Couldn't save the settings`,
+	msgSettingTime:           `время is HH:MM`,
+	msgSettingPunishment:     `unknown punishment, pick one of: {{.names}}`,
+	msgSettingNotify:         `уведомлять is да (yes) or нет (no)`,
+	msgSettingMentors:        `менторы is a chat ID number`,
+	msgSettingTimezone:       `unknown timezone {{.value}}, use a name like Asia/Bishkek`,
+	msgSettingWorkdays:       `дни are comma separated weekdays: пн,вт,ср,чт,пт`,
+	msgSettingReminders:      `напоминать is comma separated minutes before the deadline, e.g. 60,15, or нет (no)`,
+	msgSettingPrivately:      `лично is да (yes) or нет (no)`,
+	msgSettingBonus:          `бонус is a number of standups in a row or нет (no)`,
+	msgSettingMaxLives:       `максимум must be at least 1`,
+	msgSettingLanguages:      `языки are comma separated codes of standup languages, available: {{.codes}}`,
+	msgSettingLocale:         `unknown locale {{.value}}, pick one of: {{.locales}}`,
+	msgSettingShared:         `общий is да (yes) or нет (no)`,
+	msgSettingLives:          `жизни must be at least 1`,
+	msgDayOffWeekend:         `day off`,
+	msgDayOffFormat:          `Format: {{.command}} YYYY-MM-DD`,
+	msgDayOffAlready:         `{{.date}} is a day off already`,
+	msgDayOffRemoved:         `{{.date}} is a working day again`,
+	msgDayOffNotOff:          `{{.date}} was not a day off`,
+	msgDayOffAdded:           `OK, nobody is punished on {{.date}}`,
+	msgDaysOff:               `Days off:`,
+	msgDaysOffNone:           `No holidays within a month. Working days: {{.workdays}}`,
+	msgDaysOffAbsent:         `Away:`,
+	msgAbsenceVacationFormat: `Format: отпуск @user YYYY-MM-DD YYYY-MM-DD`,
+	msgAbsenceSickFormat:     `Format: болеет @user [YYYY-MM-DD]`,
+	msgAbsenceReturnedFormat: `Format: вернулся @user`,
+	msgAbsenceOrder:          `An absence can't end before it starts`,
+	msgAbsenceUnknown:        `I'm not watching @{{.user}}`,
+	msgAbsenceNotSaved:       `Couldn't save it, standups are still due`,
+	msgAbsenceSaved:          `OK, {{.absence}}, nobody is punished`,
+	msgAbsenceNotAway:        `@{{.user}} wasn't away`,
+	msgAbsenceReturned:       `Welcome back, @{{.user}}! Waiting for your standup.`,
+	msgAbsenceVacation:       `@{{.user}} is on vacation {{if eq .since .until}}on {{.since}}{{else}}from {{.since}} to {{.until}}{{end}}`,
+	msgAbsenceSick:           `@{{.user}} is sick {{if eq .since .until}}on {{.since}}{{else}}from {{.since}} to {{.until}}{{end}}`,
+	msgDebtNotIntern:         `@{{.user}}, you're not an intern, no need to work out`,
+	msgDebtNothingOwed:       `@{{.user}}, you don't owe anybody {{.amount}}`,
+	msgDebtCounted:           `@{{.user}}, counted {{.amount}}, {{.left}} left{{if .extra}}. The extra {{.extra}} are not saved for later{{end}}`,
+	msgDebtStranger:          `@{{.user}}, you're not an intern, you owe nothing`,
+	msgDebtNoneOwn:           `@{{.user}}, you owe nothing`,
+	msgDebtOwn:               `@{{.user}}, you owe: {{.debt}}`,
+	msgDebtNone:              `Nobody owes anything`,
+	msgDebts:                 `Debts:`,
+	msgExportFormat:          `Format: экспорт [@user] [YYYY-MM-DD [YYYY-MM-DD]] [{{.formats}}]`,
+	msgExportFailed:          `Couldn't export standups`,
+	msgExportTitle:           `Standup journal`,
+	msgExportYesterday:       `Yesterday`,
+	msgExportToday:           `Today`,
+	msgExportBlockers:        `Blockers`,
+	msgExportStandup:         `standup`,
+	msgExportPunishment:      `punishment`,
+	msgExportDone:            `done`,
+	msgProofUnknown:          `@{{.user}}, I can't tell which punishment this is for`,
+	msgProofNotYours:         `@{{.user}}, this punishment is not yours`,
+	msgProofInReview:         `@{{.user}}, this punishment is already under review`,
+	msgProofDone:             `@{{.user}}, this punishment is already accepted`,
+	msgProofSent:             `@{{.user}}, sent it to mentors for review`,
+	msgProofAsk:              `@{{.user}} says the punishment is done: {{.punishment}}. Accept it?`,
+	msgProofConfirm:          `Accept`,
+	msgProofReject:           `Reject`,
+	msgProofMissing:          `No such punishment`,
+	msgProofAdminsOnly:       `Only group admins can review punishments`,
+	msgProofChecked:          `Already reviewed`,
+	msgProofConfirmed:        `accepted`,
+	msgProofRejected:         `rejected`,
+	msgPunishmentConfirmed:   `@{{.user}}, punishment accepted: {{.punishment}}`,
+	msgPunishmentRejected:    `@{{.user}}, punishment rejected: {{.punishment}}. Try again`,
+	msgPunishmentReadPoem:    `read a poem`,
+	msgUnfinished:            `Not done:`,
+	msgUnfinishedNone:        `All punishments are done`,
+	msgUnfinishedLine:        `@{{.user}} — {{.punishment}} since {{.date}}{{if .done}}, {{.done}} done{{end}}{{if .review}} (under review){{end}}`,
+	msgUnfinishedOlder:       `and {{.n}} older`,
+	msgRatingEmpty:           `I'm not watching anybody yet`,
+	msgRating:                `Rating:`,
+	msgRatingLine:            `{{.place}}. @{{.user}} — in a row: {{.streak}}, on time: {{.rate}}, lives: {{.lives}}`,
+	msgTexts:                 `Messages:`,
+	msgTextChanged:           `{{.key}} (changed)`,
+	msgTextUnknown:           `I don't know message {{.key}}`,
+	msgTextAdminsOnly:        `Only group admins can change messages`,
+	msgTextReset:             "Restored the default message:\n{{.text}}",
+	msgTextInvalid:           `Can't parse the template: {{.error}}`,
+	msgTextNotSaved:          `Couldn't save the message`,
+	msgTextSaved:             "OK, now {{.key}} is:\n{{.text}}",
+}
+
+// newCatalog returns messages of all locales the bot speaks
+func newCatalog() *i18n.Catalog {
+	c := i18n.NewCatalog("ru", messagesRU)
+	c.Add("en", messagesEN)
+	return c
+}
+
+// t renders message of the group in its locale, group's own text of the
+// message wins over the catalog
+func (b *Bot) t(groupID int64, key string, vars i18n.Vars) string {
+	locale := b.groupSettings(groupID).Locale
+	text, err := i18n.Render(locale, b.messageText(groupID, locale, key), vars)
+	if err != nil {
+		logrus.Errorf("rendering %s failed: %v\n", key, err)
+		text, _ = i18n.Render(locale, b.catalog.Text(locale, key), vars)
+	}
+	return text
+}
+
+// say sends message rendered in the locale of the chat's group
+func (b *Bot) say(chat int64, key string, vars i18n.Vars) {
+	b.tgAPI.Send(tgbotapi.NewMessage(chat, b.t(chat, key, vars)))
+}
+
+// messageText returns template of the message for the group
+func (b *Bot) messageText(groupID int64, locale, key string) string {
+	overrides, err := b.db.ListMessages(groupID)
+	if err != nil {
+		logrus.Errorf("ListMessages failed: %v\n", err)
+	}
+	for _, m := range overrides {
+		if m.Key == key {
+			return m.Text
+		}
+	}
+	return b.catalog.Text(locale, key)
+}
+
+// localized punishments word their messages with the catalog, so groups
+// read them in their locale
+type localized interface {
+	localize(b *Bot, intern model.Intern, amount int) string
+}
+
+func (e Exercise) localize(b *Bot, intern model.Intern, n int) string {
+	if !b.catalog.Has("punishment." + e.ID) {
+		return e.message(intern, n)
+	}
+	return b.t(intern.GroupID, "punishment."+e.ID, i18n.Vars{"user": intern.Username, "n": n})
+}
+
+func (t Task) localize(b *Bot, intern model.Intern, _ int) string {
+	return b.t(intern.GroupID, msgPunishmentTask, i18n.Vars{"user": intern.Username, "task": t.describe(b, intern.GroupID)})
+}
+
+// describe words the task in the group's locale, e.g. "свари кофе всей команде"
+func (t Task) describe(b *Bot, groupID int64) string {
+	if !b.catalog.Has("task." + t.ID) {
+		return t.Text
+	}
+	return b.t(groupID, "task."+t.ID, nil)
+}
+
+// amount tells how many repetitions of exercise are meant, e.g. "73 отжимания"
+func (b *Bot) amount(groupID int64, e Exercise, n int) string {
+	if !b.catalog.Has("unit." + e.ID) {
+		return fmt.Sprintf("%d %s", n, e.unit())
+	}
+	return b.t(groupID, "unit."+e.ID, i18n.Vars{"n": n})
+}
+
+// templateCommand shows how to write a standup in the group's locale
+func (b *Bot) templateCommand(channel int64) {
+	b.tgAPI.Send(tgbotapi.NewMessage(channel, b.t(channel, msgStandupTemplate, i18n.Vars{"bot": b.self.UserName})))
+}
+
+// textCommand handles "текст [key [template|сброс]]": lists messages, shows
+// one of them or, for admins, changes it for the group
+func (b *Bot) textCommand(message *tgbotapi.Message, args []string, isAdmin bool) {
+	channel := message.Chat.ID
+	overrides, err := b.db.ListMessages(channel)
+	if err != nil {
+		logrus.Errorf("ListMessages failed: %v\n", err)
+		return
+	}
+	if len(args) == 0 {
+		changed := map[string]bool{}
+		for _, m := range overrides {
+			changed[m.Key] = true
+		}
+		lines := []string{}
+		for _, key := range b.catalog.Keys() {
+			if changed[key] {
+				key = b.t(channel, msgTextChanged, i18n.Vars{"key": key})
+			}
+			lines = append(lines, key)
+		}
+		b.tgAPI.Send(tgbotapi.NewMessage(channel, b.t(channel, msgTexts, nil)+"\n"+strings.Join(lines, "\n")))
+		return
+	}
+	key := args[0]
+	if !b.catalog.Has(key) {
+		b.say(channel, msgTextUnknown, i18n.Vars{"key": key})
+		return
+	}
+	locale := b.groupSettings(channel).Locale
+	if len(args) == 1 {
+		b.tgAPI.Send(tgbotapi.NewMessage(channel, b.messageText(channel, locale, key)))
+		return
+	}
+	if !isAdmin {
+		b.say(channel, msgTextAdminsOnly, nil)
+		return
+	}
+	if len(args) == 2 && args[1] == "сброс" {
+		if err := b.db.DeleteMessage(channel, key); err != nil {
+			logrus.Errorf("DeleteMessage failed: %v\n", err)
+			return
+		}
+		b.say(channel, msgTextReset, i18n.Vars{"text": b.catalog.Text(locale, key)})
+		return
+	}
+	// the template keeps its line breaks, so take it from the raw text
+	text := dropFields(message.Text, 3)
+	if err := i18n.Check(locale, text); err != nil {
+		b.say(channel, msgTextInvalid, i18n.Vars{"error": err})
+		return
+	}
+	if htmlMessages[key] {
+		if err := checkHTML(text); err != nil {
+			b.say(channel, msgTextInvalid, i18n.Vars{"error": err})
+			return
+		}
+	}
+	if err := b.db.SetMessage(model.Message{GroupID: channel, Key: key, Text: text}); err != nil {
+		logrus.Errorf("SetMessage failed: %v\n", err)
+		b.say(channel, msgTextNotSaved, nil)
+		return
+	}
+	b.say(channel, msgTextSaved, i18n.Vars{"key": key, "text": text})
+}
+
+// htmlMessages are sent with HTML parse mode, their texts must be valid
+// Telegram HTML
+var htmlMessages = map[string]bool{
+	msgDigestTitle:    true,
+	msgDigestIntern:   true,
+	msgDigestBlockers: true,
+}
+
+// htmlTags are the tags Telegram supports in HTML parse mode
+var htmlTags = map[string]bool{
+	"b": true, "strong": true, "i": true, "em": true, "u": true, "ins": true,
+	"s": true, "strike": true, "del": true, "a": true, "code": true, "pre": true,
+}
+
+// checkHTML fails when text has tags Telegram does not support, unclosed
+// tags or "<" and "&" that are not escaped
+func checkHTML(text string) error {
+	open := []string{}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '<':
+			end := strings.IndexByte(text[i:], '>')
+			if end < 0 {
+				return errors.New("unescaped <, write &lt;")
+			}
+			tag := text[i+1 : i+end]
+			closing := strings.HasPrefix(tag, "/")
+			fields := strings.Fields(strings.TrimPrefix(tag, "/"))
+			if len(fields) == 0 || !htmlTags[strings.ToLower(fields[0])] {
+				return fmt.Errorf("unsupported tag <%s>", tag)
+			}
+			name := strings.ToLower(fields[0])
+			if !closing {
+				open = append(open, name)
+			} else if len(open) == 0 || open[len(open)-1] != name {
+				return fmt.Errorf("unexpected </%s>", name)
+			} else {
+				open = open[:len(open)-1]
+			}
+			i += end
+		case '&':
+			end := strings.IndexByte(text[i:], ';')
+			if end < 0 || html.UnescapeString(text[i:i+end+1]) == text[i:i+end+1] {
+				return errors.New("unescaped &, write &amp;")
+			}
+			i += end
+		}
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("unclosed <%s>", open[len(open)-1])
+	}
+	return nil
+}
+
+// dropFields returns s without n first whitespace separated fields
+func dropFields(s string, n int) string {
+	for i := 0; i < n; i++ {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		end := strings.IndexFunc(s, unicode.IsSpace)
+		if end < 0 {
+			return ""
+		}
+		s = s[end:]
+	}
+	return strings.TrimSpace(s)
+}
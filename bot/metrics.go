@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"strings"
+
+	"github.com/maddevsio/punisher/metrics"
+	"github.com/maddevsio/punisher/standup"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// sectionLabels name standup sections in metric labels
+var sectionLabels = map[standup.Section]string{
+	standup.Yesterday: "yesterday",
+	standup.Today:     "today",
+	standup.Blockers:  "blockers",
+}
+
+// rejectReason is the label of a standup lacking sections, e.g.
+// "missing_today_blockers"
+func rejectReason(missing []standup.Section) string {
+	if len(missing) == 0 {
+		return "invalid"
+	}
+	labels := []string{}
+	for _, s := range missing {
+		labels = append(labels, sectionLabels[s])
+	}
+	return "missing_" + strings.Join(labels, "_")
+}
+
+// countingMessenger counts failed Telegram calls in metrics.TelegramErrors
+type countingMessenger struct {
+	Messenger
+}
+
+func countError(method string, err error) {
+	if err != nil {
+		metrics.TelegramErrors.WithLabelValues(method).Inc()
+	}
+}
+
+func (m countingMessenger) GetMe() (tgbotapi.User, error) {
+	user, err := m.Messenger.GetMe()
+	countError("getMe", err)
+	return user, err
+}
+
+func (m countingMessenger) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	message, err := m.Messenger.Send(c)
+	countError("send", err)
+	return message, err
+}
+
+func (m countingMessenger) GetChatAdministrators(config tgbotapi.ChatConfig) ([]tgbotapi.ChatMember, error) {
+	admins, err := m.Messenger.GetChatAdministrators(config)
+	countError("getChatAdministrators", err)
+	return admins, err
+}
+
+func (m countingMessenger) KickChatMember(config tgbotapi.KickChatMemberConfig) (tgbotapi.APIResponse, error) {
+	resp, err := m.Messenger.KickChatMember(config)
+	countError("kickChatMember", err)
+	return resp, err
+}
+
+func (m countingMessenger) GetUpdates(config tgbotapi.UpdateConfig) ([]tgbotapi.Update, error) {
+	updates, err := m.Messenger.GetUpdates(config)
+	countError("getUpdates", err)
+	return updates, err
+}
+
+func (m countingMessenger) AnswerCallbackQuery(config tgbotapi.CallbackConfig) (tgbotapi.APIResponse, error) {
+	resp, err := m.Messenger.AnswerCallbackQuery(config)
+	countError("answerCallbackQuery", err)
+	return resp, err
+}
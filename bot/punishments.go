@@ -0,0 +1,222 @@
+package bot
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/model"
+)
+
+// randomPunishment is the punishment type that picks a registered
+// punishment at random according to weights
+const randomPunishment = "random"
+
+// punishmentRand rolls repetitions and picks random punishments, it is
+// seeded once instead of reseeding the global source on every NewTGBot
+var punishmentRand = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// intn is rand.Intn of punishmentRand, safe for concurrent use
+func intn(n int) int {
+	punishmentRand.Lock()
+	defer punishmentRand.Unlock()
+	return punishmentRand.Intn(n)
+}
+
+// Punishment is a penalty for a missed standup
+type Punishment interface {
+	// Name identifies punishment in PUNISHMENT_TYPE and group settings
+	Name() string
+	// Apply punishes intern and returns amount of work to do, if it can be
+	// counted, and text the bot posts to the group
+	Apply(intern model.Intern) (int, string, error)
+}
+
+// immediate punishments are carried out by the bot itself, so interns do not
+// need to prove anything
+type immediate interface {
+	immediate()
+}
+
+// Exercise makes intern do a random number of repetitions from Min to Max
+type Exercise struct {
+	ID       string
+	Min, Max int
+	// Text is a format with intern's username and number of repetitions,
+	// it is used when the catalog has no "punishment.<ID>" message
+	Text string
+	// Unit names repetitions in reports, e.g. "отжиманий", when the catalog
+	// has no "unit.<ID>" message
+	Unit string
+}
+
+// Name implements Punishment
+func (e Exercise) Name() string {
+	return e.ID
+}
+
+// Apply implements Punishment
+func (e Exercise) Apply(intern model.Intern) (int, string, error) {
+	n := e.roll()
+	return n, e.message(intern, n), nil
+}
+
+func (e Exercise) roll() int {
+	if e.Max <= e.Min {
+		return e.Min
+	}
+	return intn(e.Max-e.Min+1) + e.Min
+}
+
+func (e Exercise) message(intern model.Intern, n int) string {
+	if e.Text == "" {
+		return fmt.Sprintf("@%s: %d %s", intern.Username, n, e.unit())
+	}
+	return fmt.Sprintf(e.Text, intern.Username, n)
+}
+
+func (e Exercise) unit() string {
+	if e.Unit == "" {
+		return e.ID
+	}
+	return e.Unit
+}
+
+// Task is a punishment that is the same every time, e.g. "make coffee for the team"
+type Task struct {
+	ID string
+	// Text is what intern has to do, it follows the intern's mention. The
+	// catalog may word it for other locales as "task.<ID>".
+	Text string
+}
+
+// Name implements Punishment
+func (t Task) Name() string {
+	return t.ID
+}
+
+// Apply implements Punishment
+func (t Task) Apply(intern model.Intern) (int, string, error) {
+	return 0, fmt.Sprintf("@%s %s", intern.Username, t.Text), nil
+}
+
+var (
+	pushUps    = Exercise{ID: "pushups", Min: 5, Max: 100}
+	snowFlakes = Exercise{ID: "snowflakes", Min: 10, Max: 150}
+	sitUps     = Exercise{ID: "situps", Min: 20, Max: 200}
+)
+
+// poetry makes intern read a random poem from stihi.ru aloud
+type poetry struct {
+	b *Bot
+}
+
+func (poetry) Name() string {
+	return "poetry"
+}
+
+func (p poetry) Apply(intern model.Intern) (int, string, error) {
+	return 1, p.b.t(intern.GroupID, msgPunishmentPoetry, i18n.Vars{"user": intern.Username, "link": generatePoetryLink()}), nil
+}
+
+// lives takes one of intern's lives and kicks intern who has none left
+type lives struct {
+	b *Bot
+}
+
+func (lives) Name() string {
+	return "removelives"
+}
+
+func (l lives) Apply(intern model.Intern) (int, string, error) {
+	text, err := l.b.takeLife(intern)
+	return 1, text, err
+}
+
+func (lives) immediate() {}
+
+// Registry keeps punishments by name. It is filled before the bot starts
+// and is not safe for concurrent registration.
+type Registry struct {
+	names       []string
+	punishments map[string]Punishment
+	weights     map[string]int
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		punishments: map[string]Punishment{},
+		weights:     map[string]int{},
+	}
+}
+
+// Register adds p replacing punishment with the same name. Weight is how
+// likely p is picked by Random relative to others, 0 never picks it.
+func (r *Registry) Register(p Punishment, weight int) {
+	if _, ok := r.punishments[p.Name()]; !ok {
+		r.names = append(r.names, p.Name())
+	}
+	r.punishments[p.Name()] = p
+	r.weights[p.Name()] = weight
+}
+
+// Get returns punishment by name
+func (r *Registry) Get(name string) (Punishment, bool) {
+	p, ok := r.punishments[name]
+	return p, ok
+}
+
+// Names lists registered punishments in order of registration
+func (r *Registry) Names() []string {
+	return append([]string{}, r.names...)
+}
+
+// SetWeights parses weights like "pushups:2,removelives:0"
+func (r *Registry) SetWeights(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		kv := strings.SplitN(part, ":", 2)
+		name := strings.TrimSpace(kv[0])
+		if _, ok := r.punishments[name]; !ok {
+			return fmt.Errorf("unknown punishment %q", name)
+		}
+		if len(kv) < 2 {
+			return fmt.Errorf("no weight for %q", name)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil || weight < 0 {
+			return fmt.Errorf("bad weight for %q", name)
+		}
+		r.weights[name] = weight
+	}
+	return nil
+}
+
+// Random picks a punishment with probability proportional to its weight
+func (r *Registry) Random() (Punishment, bool) {
+	total := 0
+	for _, name := range r.names {
+		total += r.weights[name]
+	}
+	if total == 0 {
+		return nil, false
+	}
+	n := intn(total)
+	for _, name := range r.names {
+		if n < r.weights[name] {
+			return r.punishments[name], true
+		}
+		n -= r.weights[name]
+	}
+	return nil, false
+}
@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maddevsio/punisher/calendar"
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/model"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// noReminders is the value of reminders setting that disables them
+const noReminders = "нет"
+
+// parseReminders parses comma separated minutes before the deadline,
+// largest first. "нет" means no reminders.
+func parseReminders(s string) ([]int, error) {
+	if strings.TrimSpace(s) == noReminders {
+		return []int{}, nil
+	}
+	minutes := []int{}
+	seen := map[int]bool{}
+	for _, part := range strings.Split(s, ",") {
+		m, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || m < 1 || m >= 24*60 {
+			return nil, fmt.Errorf("bad reminder %q", part)
+		}
+		if !seen[m] {
+			seen[m] = true
+			minutes = append(minutes, m)
+		}
+	}
+	if len(minutes) == 0 {
+		return nil, errors.New("no reminders given")
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(minutes)))
+	return minutes, nil
+}
+
+// formatReminders formats minutes as "60,15", parseReminders accepts it back
+func formatReminders(minutes []int) string {
+	if len(minutes) == 0 {
+		return noReminders
+	}
+	parts := make([]string, len(minutes))
+	for i, m := range minutes {
+		parts[i] = strconv.Itoa(m)
+	}
+	return strings.Join(parts, ",")
+}
+
+// remind mentions interns of the group who have not submitted standup for
+// the deadline yet and, if the group wants it, writes to them privately
+func (b *Bot) remind(group model.Group, minutes int, deadline time.Time) error {
+	now := deadline
+	if _, off := b.dayOff(group, now); off {
+		return errDayOff
+	}
+	interns, err := b.db.ListGroupInterns(group.ID)
+	if err != nil {
+		return err
+	}
+	today := calendar.Day(now)
+	absences, err := b.absencesFrom(group.ID, today)
+	if err != nil {
+		return err
+	}
+	lazy := []model.Intern{}
+	for _, intern := range interns {
+		if _, ok := activeAbsence(absences[intern.ID], today); ok {
+			continue
+		}
+		submitted, err := b.submittedToday(intern, now)
+		if err != nil {
+			return err
+		}
+		if !submitted {
+			lazy = append(lazy, intern)
+		}
+	}
+	if len(lazy) == 0 {
+		return nil
+	}
+	mentions := make([]string, len(lazy))
+	for i, intern := range lazy {
+		mentions[i] = "@" + intern.Username
+	}
+	b.tgAPI.Send(tgbotapi.NewMessage(group.ID, b.t(group.ID, msgReminderGroup, i18n.Vars{"users": strings.Join(mentions, " "), "minutes": minutes})))
+	if !enabled(group.RemindPrivately) {
+		return nil
+	}
+	for _, intern := range lazy {
+		if intern.UserID == 0 {
+			continue
+		}
+		// fails unless intern has started a private chat with the bot
+		_, err := b.tgAPI.Send(tgbotapi.NewMessage(intern.UserID, b.t(group.ID, msgReminderPrivate, i18n.Vars{"minutes": minutes})))
+		if err != nil {
+			logrus.Warnf("Private reminder to %s failed: %v\n", intern.Username, err)
+		}
+	}
+	return nil
+}
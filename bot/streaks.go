@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/maddevsio/punisher/i18n"
+	"github.com/maddevsio/punisher/model"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// updateStreak counts daily check of intern and awards a bonus life when
+// the streak reaches a multiple of group's StreakBonus. It returns updated
+// intern and announcement of the bonus, if any.
+func (b *Bot) updateStreak(group model.Group, intern model.Intern, onTime bool) (model.Intern, string, error) {
+	intern.Checks++
+	bonus := ""
+	if onTime {
+		intern.Streak++
+		intern.OnTime++
+		if group.StreakBonus > 0 && intern.Streak%group.StreakBonus == 0 && intern.Lives < group.MaxLives {
+			intern.Lives++
+			bonus = b.t(group.ID, msgStreakBonus, i18n.Vars{"user": intern.Username, "streak": intern.Streak, "lives": intern.Lives})
+		}
+	} else {
+		intern.Streak = 0
+	}
+	_, err := b.db.UpdateIntern(intern)
+	return intern, bonus, err
+}
+
+// onTimeRate is the share of checks intern passed
+func onTimeRate(intern model.Intern) float64 {
+	if intern.Checks == 0 {
+		return 0
+	}
+	return float64(intern.OnTime) / float64(intern.Checks)
+}
+
+// rating posts leaderboard of the group: longest streaks first, then
+// better submission rate, then more lives
+func (b *Bot) rating(channel int64) {
+	interns, err := b.db.ListGroupInterns(channel)
+	if err != nil {
+		logrus.Errorf("ListGroupInterns failed: %v\n", err)
+		return
+	}
+	if len(interns) == 0 {
+		b.say(channel, msgRatingEmpty, nil)
+		return
+	}
+	sort.SliceStable(interns, func(i, j int) bool {
+		a, c := interns[i], interns[j]
+		if a.Streak != c.Streak {
+			return a.Streak > c.Streak
+		}
+		if onTimeRate(a) != onTimeRate(c) {
+			return onTimeRate(a) > onTimeRate(c)
+		}
+		return a.Lives > c.Lives
+	})
+	lines := make([]string, len(interns))
+	for i, intern := range interns {
+		rate := "—"
+		if intern.Checks > 0 {
+			rate = fmt.Sprintf("%.0f%% (%d/%d)", onTimeRate(intern)*100, intern.OnTime, intern.Checks)
+		}
+		lines[i] = b.t(channel, msgRatingLine, i18n.Vars{
+			"place":  i + 1,
+			"user":   intern.Username,
+			"streak": intern.Streak,
+			"rate":   rate,
+			"lives":  intern.Lives,
+		})
+	}
+	b.tgAPI.Send(tgbotapi.NewMessage(channel, b.t(channel, msgRating, nil)+"\n"+strings.Join(lines, "\n")))
+}
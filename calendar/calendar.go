@@ -0,0 +1,293 @@
+// Package calendar knows which days are not working days:
+// public holidays loaded from iCal (.ics) or YAML files and working weekdays.
+package calendar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultWorkdays is Monday to Friday
+var DefaultWorkdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// Holiday is a day (or several days) off
+type Holiday struct {
+	Name string
+	// From and To are inclusive dates, see Day
+	From time.Time
+	To   time.Time
+	// Yearly holidays repeat every year on the same dates
+	Yearly bool
+}
+
+// Covers reports whether day falls into the holiday
+func (h Holiday) Covers(day time.Time) bool {
+	day = Day(day)
+	if !h.Yearly {
+		return !day.Before(h.From) && !day.After(h.To)
+	}
+	from := time.Date(day.Year(), h.From.Month(), h.From.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.Add(h.To.Sub(h.From))
+	if from.After(day) {
+		// range started last year, e.g. Dec 31 - Jan 2
+		from = from.AddDate(-1, 0, 0)
+		to = from.Add(h.To.Sub(h.From))
+	}
+	return !day.Before(from) && !day.After(to)
+}
+
+// Calendar is a set of holidays
+type Calendar struct {
+	Holidays []Holiday
+}
+
+// Holiday returns holiday that covers the date of t
+func (c *Calendar) Holiday(t time.Time) (Holiday, bool) {
+	if c == nil {
+		return Holiday{}, false
+	}
+	for _, h := range c.Holidays {
+		if h.Covers(t) {
+			return h, true
+		}
+	}
+	return Holiday{}, false
+}
+
+// Day returns date of t (in t's location) as midnight UTC.
+// All dates in this package and in storage are kept this way.
+func Day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// ParseDate parses YYYY-MM-DD date
+func ParseDate(s string) (time.Time, error) {
+	return time.Parse("2006-01-02", s)
+}
+
+// Load reads holidays from .ics, .yaml or .yml file
+func Load(path string) (*Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var holidays []Holiday
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ics", ".ical":
+		holidays, err = ParseICal(f)
+	case ".yaml", ".yml":
+		holidays, err = ParseYAML(f)
+	default:
+		return nil, fmt.Errorf("unknown holidays file format: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &Calendar{Holidays: holidays}, nil
+}
+
+type yamlHoliday struct {
+	Date   string `yaml:"date"`
+	To     string `yaml:"to"`
+	Name   string `yaml:"name"`
+	Yearly bool   `yaml:"yearly"`
+}
+
+// ParseYAML reads holidays from YAML list like
+//
+//   - date: 2026-01-01
+//     to: 2026-01-02 # optional last day
+//     name: New Year
+//     yearly: true
+func ParseYAML(r io.Reader) ([]Holiday, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var items []yamlHoliday
+	if err := yaml.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	holidays := []Holiday{}
+	for _, item := range items {
+		from, err := ParseDate(item.Date)
+		if err != nil {
+			return nil, fmt.Errorf("holiday %q: %v", item.Name, err)
+		}
+		to := from
+		if item.To != "" {
+			if to, err = ParseDate(item.To); err != nil {
+				return nil, fmt.Errorf("holiday %q: %v", item.Name, err)
+			}
+		}
+		if to.Before(from) {
+			return nil, fmt.Errorf("holiday %q ends before it starts", item.Name)
+		}
+		holidays = append(holidays, Holiday{Name: item.Name, From: from, To: to, Yearly: item.Yearly})
+	}
+	return holidays, nil
+}
+
+// ParseICal reads VEVENTs from iCalendar data. Only dates matter, so time
+// of day is dropped; yearly RRULE is supported, other rules are ignored.
+func ParseICal(r io.Reader) ([]Holiday, error) {
+	lines, err := unfoldICal(r)
+	if err != nil {
+		return nil, err
+	}
+	holidays := []Holiday{}
+	var event *Holiday
+	var hasEnd bool
+	for n, line := range lines {
+		name, value := splitICalLine(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event, hasEnd = &Holiday{}, false
+		case event == nil:
+			continue
+		case name == "END" && value == "VEVENT":
+			if event.From.IsZero() {
+				return nil, fmt.Errorf("line %d: event %q has no DTSTART", n+1, event.Name)
+			}
+			if !hasEnd {
+				event.To = event.From
+			}
+			holidays = append(holidays, *event)
+			event = nil
+		case name == "SUMMARY":
+			event.Name = unescapeICal(value)
+		case name == "DTSTART":
+			if event.From, err = parseICalDate(value); err != nil {
+				return nil, fmt.Errorf("line %d: %v", n+1, err)
+			}
+		case name == "DTEND":
+			end, err := parseICalDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n+1, err)
+			}
+			// DTEND is exclusive
+			event.To, hasEnd = end.AddDate(0, 0, -1), true
+			if event.To.Before(event.From) {
+				event.To = event.From
+			}
+		case name == "RRULE":
+			event.Yearly = strings.Contains(value, "FREQ=YEARLY")
+		}
+	}
+	if event != nil {
+		return nil, errors.New("unterminated VEVENT")
+	}
+	return holidays, nil
+}
+
+// unfoldICal joins continuation lines (starting with space or tab)
+func unfoldICal(r io.Reader) ([]string, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitICalLine splits "DTSTART;VALUE=DATE:20260101" into "DTSTART" and "20260101"
+func splitICalLine(line string) (string, string) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return line, ""
+	}
+	name := line[:i]
+	if j := strings.Index(name, ";"); j >= 0 {
+		name = name[:j]
+	}
+	return strings.ToUpper(name), line[i+1:]
+}
+
+func parseICalDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("bad date %q", value)
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+var icalUnescaper = strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescapeICal(s string) string {
+	return icalUnescaper.Replace(s)
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"пн": time.Monday, "вт": time.Tuesday, "ср": time.Wednesday, "чт": time.Thursday,
+	"пт": time.Friday, "сб": time.Saturday, "вс": time.Sunday,
+	"mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday,
+	"fri": time.Friday, "sat": time.Saturday, "sun": time.Sunday,
+}
+
+var shortWeekdays = []string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"}
+
+// ParseWeekdays parses comma separated weekdays: "пн,вт,ср", "mon,tue"
+// or Go weekday numbers "1,2,3" (0 is Sunday)
+func ParseWeekdays(s string) ([]time.Weekday, error) {
+	days := []time.Weekday{}
+	seen := map[time.Weekday]bool{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		day, ok := weekdayNames[part]
+		if !ok {
+			n, err := strconv.Atoi(part)
+			if err != nil || n < 0 || n > 6 {
+				return nil, fmt.Errorf("unknown weekday %q", part)
+			}
+			day = time.Weekday(n)
+		}
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	if len(days) == 0 {
+		return nil, errors.New("no weekdays given")
+	}
+	sort.Slice(days, func(i, j int) bool { return (days[i]+6)%7 < (days[j]+6)%7 })
+	return days, nil
+}
+
+// FormatWeekdays formats days as "пн,вт,ср", ParseWeekdays accepts it back
+func FormatWeekdays(days []time.Weekday) string {
+	names := make([]string, len(days))
+	for i, d := range days {
+		names[i] = shortWeekdays[d]
+	}
+	return strings.Join(names, ",")
+}
+
+// IsWorkday reports whether t's weekday is among days
+func IsWorkday(days []time.Weekday, t time.Time) bool {
+	for _, d := range days {
+		if t.Weekday() == d {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package calendar
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ics = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20260101\r\n" +
+	"DTEND;VALUE=DATE:20260103\r\n" +
+	"SUMMARY:Новый\r\n" +
+	"  год\r\n" +
+	"RRULE:FREQ=YEARLY\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20260308T000000Z\r\n" +
+	"SUMMARY:8 марта\\, выходной\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+const yml = `
+- date: 2026-01-01
+  to: 2026-01-02
+  name: Новый год
+  yearly: true
+- date: 2026-03-08
+  name: 8 марта, выходной
+`
+
+func date(s string) time.Time {
+	d, _ := ParseDate(s)
+	return d
+}
+
+func TestParse(t *testing.T) {
+	fromICal, err := ParseICal(strings.NewReader(ics))
+	assert.NoError(t, err)
+	fromYAML, err := ParseYAML(strings.NewReader(yml))
+	assert.NoError(t, err)
+
+	for _, holidays := range [][]Holiday{fromICal, fromYAML} {
+		assert.Equal(t, []Holiday{
+			{Name: "Новый год", From: date("2026-01-01"), To: date("2026-01-02"), Yearly: true},
+			{Name: "8 марта, выходной", From: date("2026-03-08"), To: date("2026-03-08")},
+		}, holidays)
+	}
+
+	_, err = ParseICal(strings.NewReader("BEGIN:VEVENT\nSUMMARY:broken\nEND:VEVENT\n"))
+	assert.Error(t, err)
+	_, err = ParseYAML(strings.NewReader("- date: 01.01.2026\n"))
+	assert.Error(t, err)
+}
+
+func TestCalendarHoliday(t *testing.T) {
+	c := &Calendar{Holidays: []Holiday{
+		{Name: "Новый год", From: date("2025-12-31"), To: date("2026-01-02"), Yearly: true},
+		{Name: "8 марта", From: date("2026-03-08"), To: date("2026-03-08")},
+	}}
+	var testCases = []struct {
+		day     string
+		holiday string
+	}{
+		{"2026-03-08", "8 марта"},
+		{"2027-03-08", ""},
+		{"2030-12-31", "Новый год"},
+		{"2031-01-02", "Новый год"},
+		{"2031-01-03", ""},
+		{"2026-05-01", ""},
+	}
+	for _, tt := range testCases {
+		h, _ := c.Holiday(date(tt.day))
+		assert.Equal(t, tt.holiday, h.Name, tt.day)
+	}
+
+	// local date matters, not UTC one
+	bishkek, _ := time.LoadLocation("Asia/Bishkek")
+	_, ok := c.Holiday(time.Date(2026, 3, 8, 1, 0, 0, 0, bishkek))
+	assert.True(t, ok)
+
+	var empty *Calendar
+	_, ok = empty.Holiday(date("2026-03-08"))
+	assert.False(t, ok)
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "calendar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{"h.ics": ics, "h.yml": yml, "h.txt": ""} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	c, err := Load(filepath.Join(dir, "h.ics"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(c.Holidays))
+	c, err = Load(filepath.Join(dir, "h.yml"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(c.Holidays))
+	_, err = Load(filepath.Join(dir, "h.txt"))
+	assert.Error(t, err)
+}
+
+func TestWeekdays(t *testing.T) {
+	days, err := ParseWeekdays("пт, пн,вт,1")
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Weekday{time.Monday, time.Tuesday, time.Friday}, days)
+	assert.Equal(t, "пн,вт,пт", FormatWeekdays(days))
+
+	days, err = ParseWeekdays("sun,sat")
+	assert.NoError(t, err)
+	assert.Equal(t, "сб,вс", FormatWeekdays(days))
+
+	_, err = ParseWeekdays("пн,funday")
+	assert.Error(t, err)
+	_, err = ParseWeekdays("")
+	assert.Error(t, err)
+
+	assert.True(t, IsWorkday(DefaultWorkdays, date("2026-10-19")))
+	assert.False(t, IsWorkday(DefaultWorkdays, date("2026-10-18")))
+}
@@ -1,6 +1,12 @@
 package config
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
 
 // BotConfig ...
 type BotConfig struct {
@@ -11,11 +17,50 @@ type BotConfig struct {
 	PunishmentType string `envconfig:"PUNISHMENT_TYPE" default:"pushups"` //also can be "removelives"
 	NotifyMentors  bool   `envconfig:"NOTIFY_MENTORS" default:"false"`
 	MentorsChat    int64  `envconfig:"MENTORS_CHAT"`
+	Lives          int    `envconfig:"LIVES" default:"3"`
+	Timezone       string `envconfig:"TIMEZONE" default:"Asia/Bishkek"` // IANA name, groups may override it
+	Workdays       string `envconfig:"WORKDAYS" default:"пн,вт,ср,чт,пт"`
+	HolidaysFile   string `envconfig:"HOLIDAYS_FILE"` // .ics or .yaml
+	// Reminders are minutes before PUNISH_TIME, "нет" disables them
+	Reminders       string `envconfig:"REMINDERS" default:"60,15"`
+	RemindPrivately bool   `envconfig:"REMIND_PRIVATELY" default:"false"`
+	// PunishmentWeights changes chances of random punishments, e.g. "pushups:2,removelives:0"
+	PunishmentWeights string `envconfig:"PUNISHMENT_WEIGHTS"`
+	// StreakBonus is the number of standups in a row that earns a bonus life, 0 disables it
+	StreakBonus int `envconfig:"STREAK_BONUS" default:"5"`
+	MaxLives    int `envconfig:"MAX_LIVES" default:"5"`
+	// Languages are comma separated codes of standup language packs, e.g. "ru,en"
+	Languages string `envconfig:"LANGUAGES" default:"ru"`
+	// LanguagesDir has more packs, e.g. ky.yaml, see standup.LoadPacks
+	LanguagesDir string `envconfig:"LANGUAGES_DIR"`
+	// Locale of bot messages, groups may override it
+	Locale string `envconfig:"LOCALE" default:"ru"`
+	// SharedStandups lets interns of several groups post one standup for all of them
+	SharedStandups bool `envconfig:"SHARED_STANDUPS" default:"false"`
+	// Digest is weekday and time of the weekly digest for mentors, "нет" disables it
+	Digest string `envconfig:"DIGEST" default:"пт 18:00"`
+	// APIAddr is the address of read-only REST API, e.g. ":8080", empty disables it
+	APIAddr string `envconfig:"API_ADDR"`
+	// APIToken is the bearer token API clients must send
+	APIToken string `envconfig:"API_TOKEN"`
+	// MetricsAddr is the address of Prometheus /metrics, e.g. ":9090", empty disables it
+	MetricsAddr string `envconfig:"METRICS_ADDR"`
+	// HealthAddr is the address of /healthz and /readyz probes, empty disables them
+	HealthAddr string `envconfig:"HEALTH_ADDR" default:":8081"`
 }
 
 // GetConfig ...
 func GetConfig() (*BotConfig, error) {
 	var c BotConfig
 	err := envconfig.Process("bot", &c)
-	return &c, err
+	if err != nil {
+		return &c, err
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return &c, fmt.Errorf("invalid TIMEZONE: %v", err)
+	}
+	if c.APIAddr != "" && c.APIToken == "" {
+		return &c, errors.New("API_TOKEN is required when API_ADDR is set")
+	}
+	return &c, nil
 }
@@ -21,5 +21,24 @@ func TestConfig(t *testing.T) {
 	assert.Equal(t, "testToken", c.TelegramToken)
 	assert.Equal(t, "10:00", c.PunishTime)
 	assert.Equal(t, "pushups", c.PunishmentType)
+	assert.Equal(t, 3, c.Lives)
+	assert.Equal(t, "Asia/Bishkek", c.Timezone)
+	assert.Equal(t, "60,15", c.Reminders)
+	assert.Equal(t, 5, c.StreakBonus)
 
+	os.Setenv("BOT_TIMEZONE", "Mars/Olympus")
+	defer os.Unsetenv("BOT_TIMEZONE")
+	_, err = GetConfig()
+	assert.Error(t, err)
+	os.Unsetenv("BOT_TIMEZONE")
+
+	os.Setenv("BOT_API_ADDR", ":8080")
+	defer os.Unsetenv("BOT_API_ADDR")
+	_, err = GetConfig()
+	assert.Error(t, err)
+	os.Setenv("BOT_API_TOKEN", "secret")
+	defer os.Unsetenv("BOT_API_TOKEN")
+	c, err = GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", c.APIToken)
 }
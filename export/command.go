@@ -0,0 +1,60 @@
+package export
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/maddevsio/punisher/storage"
+)
+
+// ParseDay parses YYYY-MM-DD day, it starts at midnight in loc
+func ParseDay(s string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", s, loc)
+}
+
+// Command runs "punisher export [flags]", days are taken in timezone
+func Command(db storage.Store, args []string, stdout io.Writer, timezone string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	group := flags.Int64("group", 0, "Telegram chat ID of the group, all groups by default")
+	user := flags.String("user", "", "username of the intern, all interns by default")
+	since := flags.String("since", "", "first day, YYYY-MM-DD")
+	until := flags.String("until", "", "last day, YYYY-MM-DD")
+	format := flags.String("format", CSV, "one of "+strings.Join(Formats, ", "))
+	tz := flags.String("tz", timezone, "IANA timezone of days and times")
+	output := flags.String("o", "", "output file, standard output by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return fmt.Errorf("invalid -tz: %v", err)
+	}
+	opts := Options{GroupID: *group, Username: strings.TrimPrefix(*user, "@"), Format: *format, Location: loc}
+	if *since != "" {
+		if opts.Since, err = ParseDay(*since, loc); err != nil {
+			return fmt.Errorf("invalid -since: %v", err)
+		}
+	}
+	if *until != "" {
+		if opts.Until, err = ParseDay(*until, loc); err != nil {
+			return fmt.Errorf("invalid -until: %v", err)
+		}
+		opts.Until = opts.Until.AddDate(0, 0, 1)
+	}
+	if *output == "" {
+		return Write(stdout, db, opts)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, db, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
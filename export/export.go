@@ -0,0 +1,232 @@
+// Package export writes history of standups and punishments, e.g. for
+// performance reviews, as CSV, JSON Lines or a Markdown journal.
+package export
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maddevsio/punisher/model"
+	"github.com/maddevsio/punisher/storage"
+)
+
+// Formats of export
+const (
+	CSV      = "csv"
+	JSONL    = "jsonl"
+	Markdown = "md"
+)
+
+// Formats lists supported formats
+var Formats = []string{CSV, JSONL, Markdown}
+
+// Kinds of entries
+const (
+	KindStandup    = "standup"
+	KindPunishment = "punishment"
+)
+
+// Options select what to export, zero Group, Username, Since and Until
+// match everything
+type Options struct {
+	GroupID  int64
+	Username string
+	// Since is inclusive, Until is exclusive
+	Since time.Time
+	Until time.Time
+	// Format is CSV, JSONL or Markdown
+	Format string
+	// Location of times in CSV and Markdown, UTC when nil
+	Location *time.Location
+	// Labels word the Markdown journal, RussianLabels when nil
+	Labels *Labels
+}
+
+// Labels are words of the Markdown journal
+type Labels struct {
+	Title      string
+	Yesterday  string
+	Today      string
+	Blockers   string
+	Standup    string
+	Punishment string
+	Done       string
+}
+
+// RussianLabels word the journal in Russian, as the bot speaks by default
+var RussianLabels = Labels{
+	Title:      "Журнал стендапов",
+	Yesterday:  "Вчера",
+	Today:      "Сегодня",
+	Blockers:   "Проблемы",
+	Standup:    "стендап",
+	Punishment: "наказание",
+	Done:       "выполнено",
+}
+
+// Entry is a standup or a punishment of the history
+type Entry struct {
+	Time       time.Time         `json:"time"`
+	Kind       string            `json:"kind"`
+	GroupID    int64             `json:"groupid"`
+	Username   string            `json:"userName"`
+	Standup    *model.Standup    `json:"standup,omitempty"`
+	Punishment *model.Punishment `json:"punishment,omitempty"`
+}
+
+// Entries returns standups and punishments matching opts, oldest first.
+// Punishments of removed interns are kept without username.
+func Entries(db storage.Store, opts Options) ([]Entry, error) {
+	standups, err := db.FindStandups(storage.StandupFilter{
+		Username: opts.Username,
+		GroupID:  opts.GroupID,
+		Since:    opts.Since,
+		Until:    opts.Until,
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries := []Entry{}
+	for i := range standups {
+		s := &standups[i]
+		entries = append(entries, Entry{Time: s.Created, Kind: KindStandup, GroupID: s.GroupID, Username: s.Username, Standup: s})
+	}
+	groups := []int64{opts.GroupID}
+	if opts.GroupID == 0 {
+		if groups, err = db.ListGroups(); err != nil {
+			return nil, err
+		}
+	}
+	usernames := map[int64]string{}
+	for _, group := range groups {
+		punishments, err := db.ListPunishments(group)
+		if err != nil {
+			return nil, err
+		}
+		for i := range punishments {
+			p := &punishments[i]
+			username, ok := usernames[p.InternID]
+			if !ok {
+				intern, err := db.SelectIntern(p.InternID)
+				if err != nil && err != sql.ErrNoRows {
+					return nil, err
+				}
+				username = intern.Username
+				usernames[p.InternID] = username
+			}
+			if (opts.Username != "" && username != opts.Username) ||
+				(!opts.Since.IsZero() && p.Issued.Before(opts.Since)) ||
+				(!opts.Until.IsZero() && !p.Issued.Before(opts.Until)) {
+				continue
+			}
+			entries = append(entries, Entry{Time: p.Issued, Kind: KindPunishment, GroupID: p.GroupID, Username: username, Punishment: p})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries, nil
+}
+
+// Write writes entries matching opts to w in opts.Format
+func Write(w io.Writer, db storage.Store, opts Options) error {
+	write, ok := writers[opts.Format]
+	if !ok {
+		return fmt.Errorf("unknown format %q, choose one of %s", opts.Format, strings.Join(Formats, ", "))
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	if opts.Labels == nil {
+		opts.Labels = &RussianLabels
+	}
+	entries, err := Entries(db, opts)
+	if err != nil {
+		return err
+	}
+	return write(w, entries, opts)
+}
+
+var writers = map[string]func(io.Writer, []Entry, Options) error{
+	CSV:      writeCSV,
+	JSONL:    writeJSONL,
+	Markdown: writeMarkdown,
+}
+
+var csvHeader = []string{"time", "kind", "group", "user", "comment", "yesterday", "today", "blockers", "punishment", "amount", "done", "status"}
+
+func writeCSV(w io.Writer, entries []Entry, opts Options) error {
+	out := csv.NewWriter(w)
+	out.Write(csvHeader)
+	for _, e := range entries {
+		row := []string{e.Time.In(opts.Location).Format(time.RFC3339), e.Kind, strconv.FormatInt(e.GroupID, 10), e.Username}
+		if s := e.Standup; s != nil {
+			row = append(row, s.Comment, s.Yesterday, s.Today, s.Blockers, "", "", "", "")
+		}
+		if p := e.Punishment; p != nil {
+			row = append(row, "", "", "", "", p.Type, strconv.Itoa(p.Amount), strconv.Itoa(p.Done), p.Status)
+		}
+		out.Write(row)
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func writeJSONL(w io.Writer, entries []Entry, _ Options) error {
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdown writes a journal with a section per day
+func writeMarkdown(w io.Writer, entries []Entry, opts Options) error {
+	l := opts.Labels
+	title := "# " + l.Title
+	if opts.Username != "" {
+		title += " @" + opts.Username
+	}
+	lines := []string{title}
+	day := ""
+	for _, e := range entries {
+		t := e.Time.In(opts.Location)
+		if d := t.Format("2006-01-02"); d != day {
+			day = d
+			lines = append(lines, "", "## "+day, "")
+		}
+		who := ""
+		if opts.Username == "" && e.Username != "" {
+			who = " @" + e.Username
+		}
+		if s := e.Standup; s != nil {
+			lines = append(lines, fmt.Sprintf("- %s%s, %s", t.Format("15:04"), who, l.Standup))
+			if s.Yesterday == "" && s.Today == "" && s.Blockers == "" {
+				lines = append(lines, "  "+quote(s.Comment))
+				continue
+			}
+			for _, section := range [][2]string{{l.Yesterday, s.Yesterday}, {l.Today, s.Today}, {l.Blockers, s.Blockers}} {
+				if section[1] != "" {
+					lines = append(lines, fmt.Sprintf("  - **%s:** %s", section[0], quote(section[1])))
+				}
+			}
+		}
+		if p := e.Punishment; p != nil {
+			lines = append(lines, fmt.Sprintf("- %s%s, %s %s %d (%s %d, %s)", t.Format("15:04"), who, l.Punishment, p.Type, p.Amount, l.Done, p.Done, p.Status))
+		}
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// quote keeps multiline text inside its list item
+func quote(s string) string {
+	return strings.Replace(strings.TrimSpace(s), "\n", "\n    ", -1)
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maddevsio/punisher/model"
+	"github.com/maddevsio/punisher/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupStore(t *testing.T) storage.Store {
+	db := storage.NewMemory()
+	alice, _ := db.CreateIntern(model.Intern{Username: "alice", GroupID: -100, Lives: 3})
+	db.CreateIntern(model.Intern{Username: "bob", GroupID: -100, Lives: 3})
+	d := time.Date(2026, time.October, 19, 3, 0, 0, 0, time.UTC)
+	db.Now = func() time.Time { return d }
+	db.CreateStandup(model.Standup{Username: "alice", GroupID: -100, Comment: "Вчера: тесты\nСегодня: деплой\nПроблемы: нет",
+		Yesterday: "тесты", Today: "деплой", Blockers: "нет"})
+	db.CreateStandup(model.Standup{Username: "bob", GroupID: -100, Comment: "работаю"})
+	d = time.Date(2026, time.October, 20, 3, 30, 0, 0, time.UTC)
+	db.CreateStandup(model.Standup{Username: "alice", GroupID: -200, Comment: "другая группа"})
+	db.CreatePunishment(model.Punishment{InternID: alice.ID, GroupID: -100, Type: "pushups", Amount: 30, Done: 10,
+		Status: model.PunishmentPending, Issued: time.Date(2026, time.October, 20, 4, 0, 0, 0, time.UTC)})
+	return db
+}
+
+func TestWrite(t *testing.T) {
+	db := setupStore(t)
+	bishkek, _ := time.LoadLocation("Asia/Bishkek")
+	opts := Options{GroupID: -100, Username: "alice", Location: bishkek}
+
+	var buf bytes.Buffer
+	opts.Format = CSV
+	assert.NoError(t, Write(&buf, db, opts))
+	assert.Equal(t, "time,kind,group,user,comment,yesterday,today,blockers,punishment,amount,done,status\n"+
+		"2026-10-19T09:00:00+06:00,standup,-100,alice,\"Вчера: тесты\nСегодня: деплой\nПроблемы: нет\",тесты,деплой,нет,,,,\n"+
+		"2026-10-20T10:00:00+06:00,punishment,-100,alice,,,,,pushups,30,10,pending\n", buf.String())
+
+	buf.Reset()
+	opts.Format = JSONL
+	assert.NoError(t, Write(&buf, db, opts))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.Contains(t, lines[0], `"kind":"standup"`)
+	assert.Contains(t, lines[0], `"today":"деплой"`)
+	assert.Contains(t, lines[1], `"kind":"punishment"`)
+	assert.NotContains(t, lines[1], `"standup"`)
+
+	buf.Reset()
+	opts.Format = Markdown
+	assert.NoError(t, Write(&buf, db, opts))
+	assert.Equal(t, "# Журнал стендапов @alice\n\n"+
+		"## 2026-10-19\n\n"+
+		"- 09:00, стендап\n  - **Вчера:** тесты\n  - **Сегодня:** деплой\n  - **Проблемы:** нет\n\n"+
+		"## 2026-10-20\n\n"+
+		"- 10:00, наказание pushups 30 (выполнено 10, pending)\n", buf.String())
+
+	assert.Error(t, Write(&buf, db, Options{Format: "xml"}))
+}
+
+func TestEntries(t *testing.T) {
+	db := setupStore(t)
+	entries, err := Entries(db, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(entries))
+
+	entries, err = Entries(db, Options{GroupID: -100})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+
+	entries, err = Entries(db, Options{Since: time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	entries, err = Entries(db, Options{Until: time.Date(2026, time.October, 20, 4, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+
+	// punishments of removed interns stay in the history
+	alice, _ := db.FindIntern("alice", -100)
+	assert.NoError(t, db.DeleteIntern(alice.ID))
+	entries, err = Entries(db, Options{GroupID: -100})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, KindPunishment, entries[2].Kind)
+	assert.Equal(t, "", entries[2].Username)
+}
+
+func TestCommand(t *testing.T) {
+	db := setupStore(t)
+	var buf bytes.Buffer
+	assert.NoError(t, Command(db, []string{"-user", "@bob", "-format", "md", "-since", "2026-10-19", "-until", "2026-10-19"}, &buf, "Asia/Bishkek"))
+	assert.Equal(t, "# Журнал стендапов @bob\n\n## 2026-10-19\n\n- 09:00, стендап\n  работаю\n", buf.String())
+
+	dir, err := ioutil.TempDir("", "export")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "alice.jsonl")
+	assert.NoError(t, Command(db, []string{"-user", "alice", "-format", "jsonl", "-o", path}, &buf, "UTC"))
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(string(data), "\n"))
+
+	assert.Error(t, Command(db, []string{"-since", "вчера"}, &buf, "UTC"))
+	assert.Error(t, Command(db, []string{"-tz", "Mars/Olympus"}, &buf, "UTC"))
+}
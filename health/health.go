@@ -0,0 +1,118 @@
+// Package health serves liveness and readiness probes, e.g. for Docker
+// HEALTHCHECK or Kubernetes. /healthz answers while the process serves HTTP,
+// /readyz runs every check and fails when any of them does.
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Check returns an error when a dependency is not ready
+type Check func() error
+
+// Checker runs named checks in the order they were added
+type Checker struct {
+	names  []string
+	checks map[string]Check
+}
+
+// New creates a Checker without checks, it is always ready
+func New() *Checker {
+	return &Checker{checks: map[string]Check{}}
+}
+
+// Add registers check under name, e.g. "db"
+func (c *Checker) Add(name string, check Check) {
+	if _, ok := c.checks[name]; !ok {
+		c.names = append(c.names, name)
+	}
+	c.checks[name] = check
+}
+
+// Report is the body of /readyz, Checks map names to "ok" or errors
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Run runs every check, ok is false when any of them failed
+func (c *Checker) Run() (Report, bool) {
+	r := Report{Status: "ok", Checks: map[string]string{}}
+	for _, name := range c.names {
+		if err := c.checks[name](); err != nil {
+			r.Status = "fail"
+			r.Checks[name] = err.Error()
+			continue
+		}
+		r.Checks[name] = "ok"
+	}
+	return r, r.Status == "ok"
+}
+
+// Handler serves /healthz and /readyz
+func (c *Checker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", c.healthz)
+	mux.HandleFunc("/readyz", c.readyz)
+	return mux
+}
+
+func (c *Checker) healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok\n"))
+}
+
+func (c *Checker) readyz(w http.ResponseWriter, r *http.Request) {
+	report, ok := c.Run()
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// URL is the address of path on the local server listening on addr,
+// e.g. ":8081" or "0.0.0.0:8081"
+func URL(addr, path string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://" + addr + path
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port) + path
+}
+
+// Probe requests url and fails unless it answers 200 OK within timeout,
+// the error carries the failed checks of a /readyz report
+func Probe(url string, timeout time.Duration) error {
+	client := http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	var report Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil || len(report.Checks) == 0 {
+		return errors.New(resp.Status)
+	}
+	failed := []string{resp.Status}
+	for name, result := range report.Checks {
+		if result != "ok" {
+			failed = append(failed, fmt.Sprintf("%s: %s", name, result))
+		}
+	}
+	sort.Strings(failed[1:])
+	return errors.New(strings.Join(failed, ", "))
+}
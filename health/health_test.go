@@ -0,0 +1,45 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker(t *testing.T) {
+	c := New()
+	db := errors.New("connection refused")
+	c.Add("db", func() error { return db })
+	c.Add("scheduler", func() error { return nil })
+	srv := httptest.NewServer(c.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/healthz")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = http.Get(srv.URL + "/readyz")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	var report Report
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	resp.Body.Close()
+	assert.Equal(t, Report{Status: "fail", Checks: map[string]string{"db": "connection refused", "scheduler": "ok"}}, report)
+	assert.EqualError(t, Probe(srv.URL+"/readyz", time.Second), "503 Service Unavailable, db: connection refused")
+
+	db = nil
+	assert.NoError(t, Probe(srv.URL+"/readyz", time.Second))
+	assert.Error(t, Probe(srv.URL+"/missing", time.Second))
+}
+
+func TestURL(t *testing.T) {
+	assert.Equal(t, "http://localhost:8081/readyz", URL(":8081", "/readyz"))
+	assert.Equal(t, "http://localhost:8081/readyz", URL("0.0.0.0:8081", "/readyz"))
+	assert.Equal(t, "http://127.0.0.1:8081/healthz", URL("127.0.0.1:8081", "/healthz"))
+}
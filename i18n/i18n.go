@@ -0,0 +1,147 @@
+// Package i18n keeps bot messages in several locales. Messages are
+// text/template templates with a "plural" function:
+//
+//	@{{.user}}, осталось {{plural .lives "жизнь" "жизни" "жизней"}}
+package i18n
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/template"
+)
+
+// Vars are values a message refers to, e.g. {{.user}}
+type Vars map[string]interface{}
+
+// pluralRules pick index of the plural form for n
+var pluralRules = map[string]func(n int) int{
+	// one, few, many: 1 жизнь, 2 жизни, 5 жизней
+	"ru": func(n int) int {
+		if n < 0 {
+			n = -n
+		}
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		}
+		return 2
+	},
+	// one, other: 1 push-up, 5 push-ups
+	"en": func(n int) int {
+		if n == 1 {
+			return 0
+		}
+		return 1
+	},
+}
+
+// Plural returns n followed by its plural form in locale. Forms are given
+// in the order of the locale's rule, locales without a rule use English one.
+func Plural(locale string, n int, forms ...string) string {
+	if len(forms) == 0 {
+		return fmt.Sprint(n)
+	}
+	rule, ok := pluralRules[locale]
+	if !ok {
+		rule = pluralRules["en"]
+	}
+	i := rule(n)
+	if i >= len(forms) {
+		i = len(forms) - 1
+	}
+	return fmt.Sprintf("%d %s", n, forms[i])
+}
+
+// Catalog keeps messages by locale and key
+type Catalog struct {
+	// fallback locale has every message
+	fallback string
+	messages map[string]map[string]string
+}
+
+// NewCatalog creates a catalog with messages of the fallback locale
+func NewCatalog(fallback string, messages map[string]string) *Catalog {
+	c := &Catalog{fallback: fallback, messages: map[string]map[string]string{}}
+	c.Add(fallback, messages)
+	return c
+}
+
+// Add adds messages of locale, messages it lacks come from the fallback
+func (c *Catalog) Add(locale string, messages map[string]string) {
+	if c.messages[locale] == nil {
+		c.messages[locale] = map[string]string{}
+	}
+	for key, text := range messages {
+		c.messages[locale][key] = text
+	}
+}
+
+// Locales lists locales in alphabetical order
+func (c *Catalog) Locales() []string {
+	locales := []string{}
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// HasLocale reports whether catalog has messages of locale
+func (c *Catalog) HasLocale(locale string) bool {
+	_, ok := c.messages[locale]
+	return ok
+}
+
+// Keys lists message keys in alphabetical order
+func (c *Catalog) Keys() []string {
+	keys := []string{}
+	for key := range c.messages[c.fallback] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Has reports whether key is a known message
+func (c *Catalog) Has(key string) bool {
+	_, ok := c.messages[c.fallback][key]
+	return ok
+}
+
+// Text returns template of the message in locale
+func (c *Catalog) Text(locale, key string) string {
+	if text, ok := c.messages[locale][key]; ok {
+		return text
+	}
+	return c.messages[c.fallback][key]
+}
+
+// Render fills template text in with vars, plural forms follow locale rules
+func Render(locale, text string, vars Vars) (string, error) {
+	t, err := parse(locale, text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Check reports syntax errors of template text
+func Check(locale, text string) error {
+	_, err := parse(locale, text)
+	return err
+}
+
+func parse(locale, text string) (*template.Template, error) {
+	return template.New("message").Funcs(template.FuncMap{
+		"plural": func(n int, forms ...string) string {
+			return Plural(locale, n, forms...)
+		},
+	}).Parse(text)
+}
@@ -0,0 +1,39 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlural(t *testing.T) {
+	for n, expected := range map[int]string{
+		1: "1 жизнь", 2: "2 жизни", 5: "5 жизней", 11: "11 жизней", 12: "12 жизней",
+		21: "21 жизнь", 22: "22 жизни", 111: "111 жизней", 0: "0 жизней",
+	} {
+		assert.Equal(t, expected, Plural("ru", n, "жизнь", "жизни", "жизней"))
+	}
+	assert.Equal(t, "1 push-up", Plural("en", 1, "push-up", "push-ups"))
+	assert.Equal(t, "5 push-ups", Plural("en", 5, "push-up", "push-ups"))
+	// unknown locales follow English
+	assert.Equal(t, "2 b", Plural("xx", 2, "a", "b"))
+	assert.Equal(t, "2", Plural("ru", 2))
+}
+
+func TestCatalog(t *testing.T) {
+	c := NewCatalog("ru", map[string]string{"hello": "привет, {{.user}}", "bye": "пока"})
+	c.Add("en", map[string]string{"hello": "hello, {{.user}}"})
+	assert.Equal(t, []string{"en", "ru"}, c.Locales())
+	assert.Equal(t, []string{"bye", "hello"}, c.Keys())
+	assert.True(t, c.Has("bye"))
+	assert.False(t, c.Has("nope"))
+	assert.True(t, c.HasLocale("en"))
+	assert.False(t, c.HasLocale("ky"))
+	assert.Equal(t, "hello, {{.user}}", c.Text("en", "hello"))
+	assert.Equal(t, "пока", c.Text("en", "bye"))
+
+	text, err := Render("ru", `{{.user}}, {{plural .n "жизнь" "жизни" "жизней"}}`, Vars{"user": "@vasya", "n": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "@vasya, 3 жизни", text)
+	assert.Error(t, Check("ru", "{{.user"))
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"time"
 
+	"github.com/maddevsio/punisher/api"
 	"github.com/maddevsio/punisher/bot"
 	"github.com/maddevsio/punisher/config"
+	"github.com/maddevsio/punisher/export"
+	"github.com/maddevsio/punisher/health"
+	"github.com/maddevsio/punisher/metrics"
+	"github.com/maddevsio/punisher/storage"
 )
 
 func main() {
@@ -12,10 +21,56 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	b, err := bot.NewTGBot(c)
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		if err := health.Probe(health.URL(c.HealthAddr, "/readyz"), 10*time.Second); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	db, err := storage.New(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := export.Command(db, os.Args[2:], os.Stdout, c.Timezone); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	db = metrics.Store(db)
+	b, err := bot.NewTGBot(c, bot.WithStore(db))
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	checker := health.New()
+	checker.Add("db", db.Ping)
+	checker.Add("schema", func() error { return storage.CheckSchema(db) })
+	checker.Add("telegram", b.CheckPolling)
+	checker.Add("scheduler", b.CheckScheduler)
+
+	// API, metrics and probes may share an address
+	muxes := map[string]*http.ServeMux{}
+	handle := func(addr, pattern string, h http.Handler) {
+		if addr == "" {
+			return
+		}
+		if muxes[addr] == nil {
+			muxes[addr] = http.NewServeMux()
+		}
+		muxes[addr].Handle(pattern, h)
+	}
+	handle(c.APIAddr, "/api/", api.New(db, c.APIToken))
+	handle(c.MetricsAddr, "/metrics", metrics.Handler(db))
+	probes := checker.Handler()
+	handle(c.HealthAddr, "/healthz", probes)
+	handle(c.HealthAddr, "/readyz", probes)
+	for addr, mux := range muxes {
+		go func(addr string, mux *http.ServeMux) {
+			log.Fatal(http.ListenAndServe(addr, mux))
+		}(addr, mux)
+	}
+
 	b.Start()
 }
@@ -0,0 +1,132 @@
+// Package metrics collects bot metrics and serves them to Prometheus
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/maddevsio/punisher/storage"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "punisher"
+
+var (
+	// UpdatesReceived counts Telegram updates the bot handled
+	UpdatesReceived = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_received_total",
+		Help:      "Telegram updates received.",
+	})
+	// StandupsAccepted counts saved standups, edits are not counted
+	StandupsAccepted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "standups_accepted_total",
+		Help:      "Standups accepted.",
+	})
+	// StandupsRejected counts standups the bot refused, e.g. reason
+	// "missing_today_blockers" or "not_saved"
+	StandupsRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "standups_rejected_total",
+		Help:      "Standups rejected by reason.",
+	}, []string{"reason"})
+	// PunishmentsIssued counts punishments by type, e.g. "pushups"
+	PunishmentsIssued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "punishments_issued_total",
+		Help:      "Punishments issued by type.",
+	}, []string{"type"})
+	// TelegramErrors counts failed Telegram Bot API calls by method
+	TelegramErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_api_errors_total",
+		Help:      "Failed Telegram Bot API calls by method.",
+	}, []string{"method"})
+	// DBQueryDuration observes storage calls by Store method, see Store
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of storage queries by method.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 4, 8),
+	}, []string{"query"})
+	// DailyJobDuration observes daily standup checks of groups
+	DailyJobDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "daily_job_duration_seconds",
+		Help:      "Duration of daily standup checks.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+	})
+	// DailyJobLastSuccess is the time of the last daily check of a group
+	// that did not fail, days off included
+	DailyJobLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "daily_job_last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful daily standup check by group.",
+	}, []string{"group"})
+)
+
+var activeInterns = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "active_interns"),
+	"Interns with lives left by group.",
+	[]string{"group"}, nil,
+)
+
+// Group formats group ID as a label value
+func Group(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// Handler serves metrics in Prometheus format, active interns are counted
+// in db on every scrape
+func Handler(db storage.Store) http.Handler {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		UpdatesReceived,
+		StandupsAccepted,
+		StandupsRejected,
+		PunishmentsIssued,
+		TelegramErrors,
+		DBQueryDuration,
+		DailyJobDuration,
+		DailyJobLastSuccess,
+		interns{db},
+	)
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
+
+// interns collects active interns per group
+type interns struct {
+	db storage.Store
+}
+
+// Describe implements prometheus.Collector
+func (c interns) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeInterns
+}
+
+// Collect implements prometheus.Collector
+func (c interns) Collect(ch chan<- prometheus.Metric) {
+	groups, err := c.db.ListGroups()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(activeInterns, err)
+		return
+	}
+	for _, group := range groups {
+		list, err := c.db.ListGroupInterns(group)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(activeInterns, err)
+			return
+		}
+		active := 0
+		for _, i := range list {
+			if i.Lives > 0 {
+				active++
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(activeInterns, prometheus.GaugeValue, float64(active), Group(group))
+	}
+}
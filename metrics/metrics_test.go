@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maddevsio/punisher/model"
+	"github.com/maddevsio/punisher/storage"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	db := Store(storage.NewMemory())
+	db.CreateIntern(model.Intern{Username: "alice", GroupID: -100, Lives: 3})
+	db.CreateIntern(model.Intern{Username: "bob", GroupID: -100, Lives: 0})
+	db.CreateIntern(model.Intern{Username: "carol", GroupID: -200, Lives: 1})
+
+	w := httptest.NewRecorder()
+	Handler(db).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	body, _ := ioutil.ReadAll(w.Body)
+	assert.Contains(t, string(body), `punisher_active_interns{group="-100"} 1`)
+	assert.Contains(t, string(body), `punisher_active_interns{group="-200"} 1`)
+	assert.Contains(t, string(body), `punisher_db_query_duration_seconds_count{query="CreateIntern"} 3`)
+	assert.Contains(t, string(body), "punisher_updates_received_total 0")
+}
+
+func TestStore(t *testing.T) {
+	db := Store(storage.NewMemory())
+	before := testutil.CollectAndCount(DBQueryDuration)
+	_, err := db.SelectIntern(1)
+	assert.Error(t, err)
+	_, err = db.ListInterns()
+	assert.NoError(t, err)
+	assert.Equal(t, before+2, testutil.CollectAndCount(DBQueryDuration))
+}
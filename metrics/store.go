@@ -0,0 +1,221 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/maddevsio/punisher/model"
+	"github.com/maddevsio/punisher/storage"
+)
+
+// Store observes latency of every db call in DBQueryDuration
+func Store(db storage.Store) storage.Store {
+	return store{db}
+}
+
+type store struct {
+	db storage.Store
+}
+
+func observe(query string, start time.Time) {
+	DBQueryDuration.WithLabelValues(query).Observe(time.Since(start).Seconds())
+}
+
+func (s store) CreateStandup(standup model.Standup) (model.Standup, error) {
+	defer observe("CreateStandup", time.Now())
+	return s.db.CreateStandup(standup)
+}
+
+func (s store) UpdateStandup(standup model.Standup) (model.Standup, error) {
+	defer observe("UpdateStandup", time.Now())
+	return s.db.UpdateStandup(standup)
+}
+
+func (s store) SelectStandup(id int64) (model.Standup, error) {
+	defer observe("SelectStandup", time.Now())
+	return s.db.SelectStandup(id)
+}
+
+func (s store) DeleteStandup(id int64) error {
+	defer observe("DeleteStandup", time.Now())
+	return s.db.DeleteStandup(id)
+}
+
+func (s store) ListStandups() ([]model.Standup, error) {
+	defer observe("ListStandups", time.Now())
+	return s.db.ListStandups()
+}
+
+func (s store) FindStandups(f storage.StandupFilter) ([]model.Standup, error) {
+	defer observe("FindStandups", time.Now())
+	return s.db.FindStandups(f)
+}
+
+func (s store) LastStandupFor(username string, groupID int64) (model.Standup, error) {
+	defer observe("LastStandupFor", time.Now())
+	return s.db.LastStandupFor(username, groupID)
+}
+
+func (s store) FindStandupByMessage(chatID int64, messageID int) (model.Standup, error) {
+	defer observe("FindStandupByMessage", time.Now())
+	return s.db.FindStandupByMessage(chatID, messageID)
+}
+
+func (s store) CreateAttachment(a model.Attachment) (model.Attachment, error) {
+	defer observe("CreateAttachment", time.Now())
+	return s.db.CreateAttachment(a)
+}
+
+func (s store) ListAttachments(standupID int64) ([]model.Attachment, error) {
+	defer observe("ListAttachments", time.Now())
+	return s.db.ListAttachments(standupID)
+}
+
+func (s store) CreateIntern(intern model.Intern) (model.Intern, error) {
+	defer observe("CreateIntern", time.Now())
+	return s.db.CreateIntern(intern)
+}
+
+func (s store) UpdateIntern(intern model.Intern) (model.Intern, error) {
+	defer observe("UpdateIntern", time.Now())
+	return s.db.UpdateIntern(intern)
+}
+
+func (s store) SelectIntern(id int64) (model.Intern, error) {
+	defer observe("SelectIntern", time.Now())
+	return s.db.SelectIntern(id)
+}
+
+func (s store) FindIntern(name string, groupID int64) (model.Intern, error) {
+	defer observe("FindIntern", time.Now())
+	return s.db.FindIntern(name, groupID)
+}
+
+func (s store) FindInternByUserID(userID int64, groupID int64) (model.Intern, error) {
+	defer observe("FindInternByUserID", time.Now())
+	return s.db.FindInternByUserID(userID, groupID)
+}
+
+func (s store) DeleteIntern(id int64) error {
+	defer observe("DeleteIntern", time.Now())
+	return s.db.DeleteIntern(id)
+}
+
+func (s store) FindInternsByUsername(name string) ([]model.Intern, error) {
+	defer observe("FindInternsByUsername", time.Now())
+	return s.db.FindInternsByUsername(name)
+}
+
+func (s store) ListInterns() ([]model.Intern, error) {
+	defer observe("ListInterns", time.Now())
+	return s.db.ListInterns()
+}
+
+func (s store) ListGroupInterns(groupID int64) ([]model.Intern, error) {
+	defer observe("ListGroupInterns", time.Now())
+	return s.db.ListGroupInterns(groupID)
+}
+
+func (s store) CreateGroup(group model.Group) (model.Group, error) {
+	defer observe("CreateGroup", time.Now())
+	return s.db.CreateGroup(group)
+}
+
+func (s store) UpdateGroup(group model.Group) (model.Group, error) {
+	defer observe("UpdateGroup", time.Now())
+	return s.db.UpdateGroup(group)
+}
+
+func (s store) SelectGroup(id int64) (model.Group, error) {
+	defer observe("SelectGroup", time.Now())
+	return s.db.SelectGroup(id)
+}
+
+func (s store) ListGroups() ([]int64, error) {
+	defer observe("ListGroups", time.Now())
+	return s.db.ListGroups()
+}
+
+func (s store) CreateDayOff(d model.DayOff) (model.DayOff, error) {
+	defer observe("CreateDayOff", time.Now())
+	return s.db.CreateDayOff(d)
+}
+
+func (s store) DeleteDayOff(id int64) error {
+	defer observe("DeleteDayOff", time.Now())
+	return s.db.DeleteDayOff(id)
+}
+
+func (s store) ListDaysOff(groupID int64) ([]model.DayOff, error) {
+	defer observe("ListDaysOff", time.Now())
+	return s.db.ListDaysOff(groupID)
+}
+
+func (s store) CreateAbsence(a model.Absence) (model.Absence, error) {
+	defer observe("CreateAbsence", time.Now())
+	return s.db.CreateAbsence(a)
+}
+
+func (s store) UpdateAbsence(a model.Absence) (model.Absence, error) {
+	defer observe("UpdateAbsence", time.Now())
+	return s.db.UpdateAbsence(a)
+}
+
+func (s store) DeleteAbsence(id int64) error {
+	defer observe("DeleteAbsence", time.Now())
+	return s.db.DeleteAbsence(id)
+}
+
+func (s store) ListAbsences(groupID int64) ([]model.Absence, error) {
+	defer observe("ListAbsences", time.Now())
+	return s.db.ListAbsences(groupID)
+}
+
+func (s store) CreatePunishment(p model.Punishment) (model.Punishment, error) {
+	defer observe("CreatePunishment", time.Now())
+	return s.db.CreatePunishment(p)
+}
+
+func (s store) UpdatePunishment(p model.Punishment) (model.Punishment, error) {
+	defer observe("UpdatePunishment", time.Now())
+	return s.db.UpdatePunishment(p)
+}
+
+func (s store) SelectPunishment(id int64) (model.Punishment, error) {
+	defer observe("SelectPunishment", time.Now())
+	return s.db.SelectPunishment(id)
+}
+
+func (s store) FindPunishmentByMessage(groupID int64, messageID int) (model.Punishment, error) {
+	defer observe("FindPunishmentByMessage", time.Now())
+	return s.db.FindPunishmentByMessage(groupID, messageID)
+}
+
+func (s store) ListPunishments(groupID int64) ([]model.Punishment, error) {
+	defer observe("ListPunishments", time.Now())
+	return s.db.ListPunishments(groupID)
+}
+
+func (s store) SetMessage(m model.Message) error {
+	defer observe("SetMessage", time.Now())
+	return s.db.SetMessage(m)
+}
+
+func (s store) DeleteMessage(groupID int64, key string) error {
+	defer observe("DeleteMessage", time.Now())
+	return s.db.DeleteMessage(groupID, key)
+}
+
+func (s store) ListMessages(groupID int64) ([]model.Message, error) {
+	defer observe("ListMessages", time.Now())
+	return s.db.ListMessages(groupID)
+}
+
+func (s store) Ping() error {
+	defer observe("Ping", time.Now())
+	return s.db.Ping()
+}
+
+func (s store) SchemaVersion() (int64, error) {
+	defer observe("SchemaVersion", time.Now())
+	return s.db.SchemaVersion()
+}
@@ -0,0 +1,6 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE `interns` ADD `userid` BIGINT NOT NULL DEFAULT 0;
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `interns` DROP `userid`;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE `groups` (
+    `id` BIGINT NOT NULL PRIMARY KEY,
+    `punishtime` VARCHAR(5) COLLATE utf8mb4_unicode_ci NOT NULL,
+    `punishmenttype` VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+    `notifymentors` BOOLEAN NOT NULL,
+    `mentorschat` BIGINT NOT NULL,
+    `lives` INTEGER NOT NULL
+);
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE `groups`;
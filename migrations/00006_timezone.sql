@@ -0,0 +1,6 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE `groups` ADD `timezone` VARCHAR(64) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '';
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `groups` DROP `timezone`;
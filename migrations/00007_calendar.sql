@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE `groups` ADD `workdays` VARCHAR(32) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '';
+CREATE TABLE `daysoff` (
+    `id` INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `groupid` BIGINT NOT NULL,
+    `date` DATE NOT NULL,
+    `reason` VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+    KEY (`groupid`, `date`)
+);
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE `daysoff`;
+ALTER TABLE `groups` DROP `workdays`;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE `absences` (
+    `id` INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `internid` INTEGER NOT NULL,
+    `groupid` BIGINT NOT NULL,
+    `kind` VARCHAR(16) NOT NULL,
+    `since` DATE NOT NULL,
+    `until` DATE NOT NULL,
+    KEY (`groupid`, `until`)
+);
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE `absences`;
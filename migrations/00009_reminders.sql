@@ -0,0 +1,8 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE `groups` ADD `reminders` VARCHAR(64) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '';
+ALTER TABLE `groups` ADD `remindprivately` BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `groups` DROP `remindprivately`;
+ALTER TABLE `groups` DROP `reminders`;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE `punishments` (
+    `id` INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `internid` INTEGER NOT NULL,
+    `groupid` BIGINT NOT NULL,
+    `type` VARCHAR(64) NOT NULL,
+    `amount` INTEGER NOT NULL,
+    `issued` DATETIME NOT NULL,
+    `status` VARCHAR(16) NOT NULL,
+    `messageid` INTEGER NOT NULL,
+    `proofmessageid` INTEGER NOT NULL,
+    KEY (`groupid`, `messageid`)
+);
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE `punishments`;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE `punishments` ADD `done` INTEGER NOT NULL DEFAULT 0;
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `punishments` DROP `done`;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE `interns` ADD `streak` INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE `interns` ADD `checks` INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE `interns` ADD `ontime` INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE `groups` ADD `streakbonus` INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE `groups` ADD `maxlives` INTEGER NOT NULL DEFAULT 0;
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `groups` DROP `maxlives`;
+ALTER TABLE `groups` DROP `streakbonus`;
+ALTER TABLE `interns` DROP `ontime`;
+ALTER TABLE `interns` DROP `checks`;
+ALTER TABLE `interns` DROP `streak`;
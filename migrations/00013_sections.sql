@@ -0,0 +1,10 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE `standup` ADD `yesterday` TEXT COLLATE utf8mb4_unicode_ci NOT NULL;
+ALTER TABLE `standup` ADD `today` TEXT COLLATE utf8mb4_unicode_ci NOT NULL;
+ALTER TABLE `standup` ADD `blockers` TEXT COLLATE utf8mb4_unicode_ci NOT NULL;
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `standup` DROP `blockers`;
+ALTER TABLE `standup` DROP `today`;
+ALTER TABLE `standup` DROP `yesterday`;
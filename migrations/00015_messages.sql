@@ -0,0 +1,13 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE `groups` ADD `locale` VARCHAR(8) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '';
+CREATE TABLE `messages` (
+    `groupid` BIGINT NOT NULL,
+    `msgkey` VARCHAR(64) NOT NULL,
+    `text` TEXT COLLATE utf8mb4_unicode_ci NOT NULL,
+    PRIMARY KEY (`groupid`, `msgkey`)
+);
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE `messages`;
+ALTER TABLE `groups` DROP `locale`;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE `standup` ADD `chatid` BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE `standup` ADD `messageid` INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX `standup_chatid_messageid` ON `standup` (`chatid`, `messageid`);
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX `standup_chatid_messageid` ON `standup`;
+ALTER TABLE `standup` DROP `messageid`;
+ALTER TABLE `standup` DROP `chatid`;
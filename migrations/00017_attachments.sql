@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE `standup` MODIFY `comment` TEXT COLLATE utf8mb4_unicode_ci NOT NULL;
+CREATE TABLE `attachments` (
+    `id` INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `standupid` INTEGER NOT NULL,
+    `type` VARCHAR(16) NOT NULL,
+    `fileid` VARCHAR(255) NOT NULL,
+    KEY (`standupid`)
+);
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE `attachments`;
+ALTER TABLE `standup` MODIFY `comment` VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL;
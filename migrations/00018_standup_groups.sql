@@ -0,0 +1,16 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE `groups` ADD `sharedstandups` BOOLEAN NOT NULL DEFAULT FALSE;
+-- standups were saved without group, the chat they were posted in is the
+-- group, older ones belong to the only group their author is enrolled in
+UPDATE `standup` SET `groupid` = `chatid` WHERE `groupid` = 0 AND `chatid` <> 0;
+UPDATE `standup` SET `groupid` = (
+    SELECT MIN(`groupid`) FROM `interns` WHERE `interns`.`username` = `standup`.`username`
+) WHERE `groupid` = 0 AND (
+    SELECT COUNT(DISTINCT `groupid`) FROM `interns` WHERE `interns`.`username` = `standup`.`username`
+) = 1;
+CREATE INDEX `standup_username_groupid` ON `standup` (`username`, `groupid`);
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX `standup_username_groupid` ON `standup`;
+ALTER TABLE `groups` DROP `sharedstandups`;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+-- NULL flags are defaults from the environment, like empty strings and zeros
+ALTER TABLE `groups` MODIFY `notifymentors` BOOLEAN NULL DEFAULT NULL;
+ALTER TABLE `groups` MODIFY `remindprivately` BOOLEAN NULL DEFAULT NULL;
+ALTER TABLE `groups` MODIFY `sharedstandups` BOOLEAN NULL DEFAULT NULL;
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+UPDATE `groups` SET `notifymentors` = FALSE WHERE `notifymentors` IS NULL;
+UPDATE `groups` SET `remindprivately` = FALSE WHERE `remindprivately` IS NULL;
+UPDATE `groups` SET `sharedstandups` = FALSE WHERE `sharedstandups` IS NULL;
+ALTER TABLE `groups` MODIFY `sharedstandups` BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE `groups` MODIFY `remindprivately` BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE `groups` MODIFY `notifymentors` BOOLEAN NOT NULL;
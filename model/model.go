@@ -2,6 +2,28 @@ package model
 
 import "time"
 
+// Kinds of absences
+const (
+	AbsenceVacation = "vacation"
+	AbsenceSick     = "sick"
+)
+
+// Types of standup attachments
+const (
+	AttachmentPhoto    = "photo"
+	AttachmentVideo    = "video"
+	AttachmentDocument = "document"
+)
+
+// Statuses of punishments
+const (
+	// PunishmentPending is not done yet, rejected punishments return here
+	PunishmentPending = "pending"
+	// PunishmentReview waits for mentors to check intern's proof
+	PunishmentReview = "review"
+	PunishmentDone   = "done"
+)
+
 type (
 	// Standup model used for serialization/deserialization stored standups
 	Standup struct {
@@ -11,13 +33,113 @@ type (
 		Username string    `db:"username" json:"userName"`
 		Comment  string    `db:"comment" json:"comment"`
 		GroupID  int64     `db:"groupid" json:"groupid"`
+		// Sections of the comment found by the standup parser
+		Yesterday string `db:"yesterday" json:"yesterday"`
+		Today     string `db:"today" json:"today"`
+		Blockers  string `db:"blockers" json:"blockers"`
+		// ChatID and MessageID point to the Telegram message, so edits of
+		// the message update the standup
+		ChatID    int64 `db:"chatid" json:"chatid"`
+		MessageID int   `db:"messageid" json:"messageid"`
+	}
+
+	// Attachment is a file posted with a standup, e.g. a screenshot of progress
+	Attachment struct {
+		ID        int64 `db:"id" json:"id"`
+		StandupID int64 `db:"standupid" json:"standupid"`
+		// Type is the kind of Telegram file: AttachmentPhoto, AttachmentVideo
+		// or AttachmentDocument
+		Type string `db:"type" json:"type"`
+		// FileID is Telegram file ID, the bot can send the file again by it
+		FileID string `db:"fileid" json:"fileid"`
 	}
 
 	// Intern rerpesents intern
 	Intern struct {
-		ID       int64  `db:"id"`
-		Username string `db:"username"`
-		Lives    int    `db:"lives"`
+		ID       int64  `db:"id" json:"id"`
+		Username string `db:"username" json:"userName"`
+		Lives    int    `db:"lives" json:"lives"`
 		GroupID  int64  `db:"groupid" json:"groupid"`
+		// UserID is Telegram user ID, 0 until intern is seen in the group
+		UserID int64 `db:"userid" json:"userid"`
+		// Streak is the number of checks in a row the intern passed
+		Streak int `db:"streak" json:"streak"`
+		// Checks counts daily checks of the intern, OnTime those passed
+		Checks int `db:"checks" json:"checks"`
+		OnTime int `db:"ontime" json:"ontime"`
+	}
+
+	// Group keeps per group settings, ID is Telegram chat ID. A group stores
+	// only settings its admins changed, zero strings and numbers and nil
+	// flags mean defaults from config.
+	Group struct {
+		ID             int64  `db:"id" json:"id"`
+		PunishTime     string `db:"punishtime" json:"punishTime"`
+		PunishmentType string `db:"punishmenttype" json:"punishmentType"`
+		NotifyMentors  *bool  `db:"notifymentors" json:"notifyMentors"`
+		MentorsChat    int64  `db:"mentorschat" json:"mentorsChat"`
+		Lives          int    `db:"lives" json:"lives"`
+		Timezone       string `db:"timezone" json:"timezone"`
+		// Workdays are comma separated working weekdays, e.g. "пн,вт,ср,чт,пт"
+		Workdays string `db:"workdays" json:"workdays"`
+		// Reminders are comma separated minutes before PunishTime, e.g. "60,15", or "нет"
+		Reminders string `db:"reminders" json:"reminders"`
+		// RemindPrivately also sends reminders to interns in private chats
+		RemindPrivately *bool `db:"remindprivately" json:"remindPrivately"`
+		// StreakBonus is the streak that earns a bonus life, 0 means default
+		// from config and negative disables bonuses
+		StreakBonus int `db:"streakbonus" json:"streakBonus"`
+		// MaxLives caps bonus lives, 0 means default from config
+		MaxLives int `db:"maxlives" json:"maxLives"`
+		// Languages are comma separated codes of standup language packs, e.g. "ru,en"
+		Languages string `db:"languages" json:"languages"`
+		// Locale of bot messages, e.g. "ru"
+		Locale string `db:"locale" json:"locale"`
+		// SharedStandups counts a standup posted in any group of the intern
+		SharedStandups *bool `db:"sharedstandups" json:"sharedStandups"`
+	}
+
+	// Message overrides text of a bot message in a group
+	Message struct {
+		GroupID int64  `db:"groupid" json:"groupid"`
+		Key     string `db:"msgkey" json:"key"`
+		Text    string `db:"text" json:"text"`
+	}
+
+	// DayOff is a one-off non working day of a group
+	DayOff struct {
+		ID      int64     `db:"id" json:"id"`
+		GroupID int64     `db:"groupid" json:"groupid"`
+		Date    time.Time `db:"date" json:"date"`
+		Reason  string    `db:"reason" json:"reason"`
+	}
+
+	// Absence excuses intern from standups from Since to Until inclusive
+	Absence struct {
+		ID       int64 `db:"id" json:"id"`
+		InternID int64 `db:"internid" json:"internid"`
+		GroupID  int64 `db:"groupid" json:"groupid"`
+		// Kind is AbsenceVacation or AbsenceSick
+		Kind  string    `db:"kind" json:"kind"`
+		Since time.Time `db:"since" json:"since"`
+		Until time.Time `db:"until" json:"until"`
+	}
+
+	// Punishment is an entry of punishments ledger
+	Punishment struct {
+		ID       int64 `db:"id" json:"id"`
+		InternID int64 `db:"internid" json:"internid"`
+		GroupID  int64 `db:"groupid" json:"groupid"`
+		// Type is the name of punishment, e.g. "pushups"
+		Type   string    `db:"type" json:"type"`
+		Amount int       `db:"amount" json:"amount"`
+		Issued time.Time `db:"issued" json:"issued"`
+		Status string    `db:"status" json:"status"`
+		// MessageID is the bot's message announcing punishment
+		MessageID int `db:"messageid" json:"messageid"`
+		// ProofMessageID is intern's photo or video showing it is done
+		ProofMessageID int `db:"proofmessageid" json:"proofmessageid"`
+		// Done is how much of Amount intern has reported as done so far
+		Done int `db:"done" json:"done"`
 	}
 )
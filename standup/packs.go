@@ -0,0 +1,214 @@
+package standup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Pack is a language pack: words and markers of every section in one language
+type Pack struct {
+	Yesterday Words `yaml:"yesterday" json:"yesterday"`
+	Today     Words `yaml:"today" json:"today"`
+	Blockers  Words `yaml:"blockers" json:"blockers"`
+}
+
+// Words start a section. Keywords are lower case word stems matched anywhere
+// in a word, "^" and "$" anchor them to the start and the end of a word.
+// Markers are emoji put before a section.
+type Words struct {
+	Keywords []string `yaml:"keywords" json:"keywords"`
+	Markers  []string `yaml:"markers" json:"markers"`
+}
+
+// Packs are language packs shipped with the bot, keyed by language code
+var Packs = Languages{
+	"ru": mustParsePack(ru),
+	"en": mustParsePack(en),
+}
+
+const ru = `
+yesterday:
+  keywords: [чера, ятницу, делал, делано]
+  markers: [✅, ☑️, ✔️]
+today:
+  keywords: [егодн, обираюс, ланир, ланы]
+  markers: [📅, 🎯, 📝, 🔜]
+blockers:
+  keywords: [роблем, рудност, атруднен, блок]
+  markers: [⛔, 🚧, 🔥, ❗, ❌]
+`
+
+const en = `
+yesterday:
+  keywords: [yesterday, friday, ^did$, ^done$, finished, completed]
+  markers: [✅, ☑️, ✔️]
+today:
+  keywords: [today, ^will$, ^plan, ^going$]
+  markers: [📅, 🎯, 📝, 🔜]
+blockers:
+  keywords: [problem, block, issue, difficult, stuck]
+  markers: [⛔, 🚧, 🔥, ❗, ❌]
+`
+
+func mustParsePack(s string) Pack {
+	p, err := ParseYAML([]byte(s))
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// ParseYAML reads a pack like
+//
+//	yesterday:
+//	  keywords: [yesterday, ^did$]
+//	  markers: [✅]
+//	today:
+//	  keywords: [today, ^will$]
+//	blockers:
+//	  keywords: [problem, stuck]
+func ParseYAML(data []byte) (Pack, error) {
+	var p Pack
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return p, err
+	}
+	return checkPack(p)
+}
+
+// ParseJSON reads a pack of the same structure as ParseYAML does
+func ParseJSON(data []byte) (Pack, error) {
+	var p Pack
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, err
+	}
+	return checkPack(p)
+}
+
+// checkPack lower cases keywords and makes sure every section has some
+func checkPack(p Pack) (Pack, error) {
+	for _, words := range []*Words{&p.Yesterday, &p.Today, &p.Blockers} {
+		for i, k := range words.Keywords {
+			if strings.Trim(k, "^$") == "" {
+				return p, fmt.Errorf("empty keyword %q", k)
+			}
+			words.Keywords[i] = strings.ToLower(k)
+		}
+	}
+	if len(p.Yesterday.Keywords) == 0 || len(p.Today.Keywords) == 0 || len(p.Blockers.Keywords) == 0 {
+		return p, fmt.Errorf("every section needs keywords")
+	}
+	return p, nil
+}
+
+// LoadPacks reads *.yaml, *.yml and *.json packs from dir, file names
+// without extension are language codes
+func LoadPacks(dir string) (map[string]Pack, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	packs := map[string]Pack{}
+	for _, f := range files {
+		ext := strings.ToLower(filepath.Ext(f.Name()))
+		if f.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		parse := ParseYAML
+		if ext == ".json" {
+			parse = ParseJSON
+		}
+		p, err := parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		packs[strings.TrimSuffix(f.Name(), filepath.Ext(f.Name()))] = p
+	}
+	return packs, nil
+}
+
+// Languages keeps available packs by language code
+type Languages map[string]Pack
+
+// NewLanguages returns shipped packs and packs from dir, if it is not empty.
+// Packs from dir replace shipped ones with the same code.
+func NewLanguages(dir string) (Languages, error) {
+	languages := Languages{}
+	for code, p := range Packs {
+		languages[code] = p
+	}
+	if dir == "" {
+		return languages, nil
+	}
+	packs, err := LoadPacks(dir)
+	if err != nil {
+		return nil, err
+	}
+	for code, p := range packs {
+		languages[code] = p
+	}
+	return languages, nil
+}
+
+// Codes lists language codes in alphabetical order
+func (l Languages) Codes() []string {
+	codes := []string{}
+	for code := range l {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// ParseCodes parses comma separated language codes like "ru,en"
+func (l Languages) ParseCodes(s string) ([]string, error) {
+	codes := []string{}
+	for _, code := range strings.Split(s, ",") {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		if _, ok := l[code]; !ok {
+			return nil, fmt.Errorf("unknown language %q", code)
+		}
+		codes = append(codes, code)
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("no languages")
+	}
+	return codes, nil
+}
+
+// Parser returns parser that understands all packs of comma separated
+// codes, unknown codes are skipped
+func (l Languages) Parser(codes string) Parser {
+	packs := []Pack{}
+	for _, code := range strings.Split(codes, ",") {
+		if p, ok := l[strings.ToLower(strings.TrimSpace(code))]; ok {
+			packs = append(packs, p)
+		}
+	}
+	return NewParser(packs...)
+}
+
+// NewParser returns parser that understands all packs
+func NewParser(packs ...Pack) Parser {
+	p := Parser{Keywords: map[Section][]string{}, Markers: map[Section][]string{}}
+	for _, pack := range packs {
+		for s, words := range map[Section]Words{Yesterday: pack.Yesterday, Today: pack.Today, Blockers: pack.Blockers} {
+			p.Keywords[s] = append(p.Keywords[s], words.Keywords...)
+			p.Markers[s] = append(p.Markers[s], words.Markers...)
+		}
+	}
+	return p
+}
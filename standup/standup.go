@@ -0,0 +1,264 @@
+// Package standup recognizes standup messages and splits them into
+// "done yesterday", "plans today" and "problems" sections.
+package standup
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Section of a standup
+type Section int
+
+// Sections of a standup, None is text before the first section
+const (
+	None Section = iota
+	Yesterday
+	Today
+	Blockers
+)
+
+var sections = []Section{Yesterday, Today, Blockers}
+
+// Parser finds sections by keywords, which are word stems matched in lower
+// case anywhere in a word unless "^" or "$" anchor them, and by emoji
+// markers put before a section
+type Parser struct {
+	Keywords map[Section][]string
+	Markers  map[Section][]string
+}
+
+// Default is the parser for Russian standups
+var Default = NewParser(Packs["ru"])
+
+// Standup is a message split into sections
+type Standup struct {
+	Yesterday string
+	Today     string
+	Blockers  string
+}
+
+// Complete reports whether every section is filled
+func (s Standup) Complete() bool {
+	return s.Yesterday != "" && s.Today != "" && s.Blockers != ""
+}
+
+// keywordWords is how many first words of a clause may hold a keyword
+// that starts a section, e.g. "Из проблем: ..."
+const keywordWords = 2
+
+// Mentions returns sections whose keywords occur anywhere in text
+func (p Parser) Mentions(text string) map[Section]bool {
+	return p.mentions(text, false)
+}
+
+func (p Parser) mentions(text string, markers bool) map[Section]bool {
+	lower := strings.ToLower(text)
+	found := map[Section]bool{}
+	for _, s := range sections {
+		for _, k := range p.Keywords[s] {
+			if matches(lower, k) {
+				found[s] = true
+			}
+		}
+		for _, m := range p.Markers[s] {
+			if markers && strings.Contains(text, m) {
+				found[s] = true
+			}
+		}
+	}
+	return found
+}
+
+// IsStandup reports whether every section is either mentioned or filled
+// under a heading or marker
+func (p Parser) IsStandup(text string) bool {
+	return len(p.Missing(text)) == 0
+}
+
+// Missing lists sections that text neither mentions nor fills
+func (p Parser) Missing(text string) []Section {
+	mentioned := p.Mentions(text)
+	parsed := p.Parse(text)
+	filled := map[Section]bool{
+		Yesterday: parsed.Yesterday != "",
+		Today:     parsed.Today != "",
+		Blockers:  parsed.Blockers != "",
+	}
+	missing := []Section{}
+	for _, s := range sections {
+		if !mentioned[s] && !filled[s] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// Parse splits text into sections. A section starts at a line, sentence
+// or, in one-line standups, a comma separated clause beginning with a
+// marker, a "Heading:" or a keyword, and lasts until the next one.
+func (p Parser) Parse(text string) Standup {
+	parts := map[Section][]string{}
+	current := None
+	for _, segment := range segments(text) {
+		clauses := []string{segment}
+		if len(p.mentions(segment, true)) > 1 {
+			clauses = strings.Split(segment, ",")
+		}
+		for _, clause := range p.splitMarkers(clauses) {
+			section, rest := p.start(clause)
+			if section != None {
+				current = section
+			}
+			rest = strings.Trim(rest, " \t,")
+			if current != None && rest != "" {
+				parts[current] = append(parts[current], rest)
+			}
+		}
+	}
+	return Standup{
+		Yesterday: strings.Join(parts[Yesterday], "\n"),
+		Today:     strings.Join(parts[Today], "\n"),
+		Blockers:  strings.Join(parts[Blockers], "\n"),
+	}
+}
+
+// splitMarkers cuts clauses before markers in their middle, so "✅ a 🎯 b"
+// gives two clauses
+func (p Parser) splitMarkers(clauses []string) []string {
+	res := []string{}
+	for _, clause := range clauses {
+		for {
+			cut := -1
+			for _, s := range sections {
+				for _, m := range p.Markers[s] {
+					i := strings.Index(clause, m)
+					if i > 0 && (cut < 0 || i < cut) {
+						cut = i
+					}
+				}
+			}
+			if cut < 0 {
+				break
+			}
+			res = append(res, clause[:cut])
+			clause = clause[cut:]
+		}
+		res = append(res, clause)
+	}
+	return res
+}
+
+// start returns section that clause starts and clause without its marker
+// or heading
+func (p Parser) start(clause string) (Section, string) {
+	clause = strings.TrimSpace(clause)
+	for _, s := range sections {
+		for _, m := range p.Markers[s] {
+			if strings.HasPrefix(clause, m) {
+				return s, strings.TrimSpace(strings.TrimPrefix(clause, m))
+			}
+		}
+	}
+	words := strings.Fields(clause)
+	if len(words) > keywordWords {
+		words = words[:keywordWords]
+	}
+	for i, word := range words {
+		s := p.keywordSection(word)
+		if s == None {
+			continue
+		}
+		if strings.HasSuffix(word, ":") {
+			// "Проблемы: ..." drops the heading
+			heading := strings.Join(words[:i+1], " ")
+			return s, strings.TrimSpace(strings.TrimPrefix(clause, heading))
+		}
+		return s, clause
+	}
+	return None, clause
+}
+
+func (p Parser) keywordSection(word string) Section {
+	word = strings.ToLower(word)
+	for _, s := range sections {
+		for _, k := range p.Keywords[s] {
+			if matches(word, k) {
+				return s
+			}
+		}
+	}
+	return None
+}
+
+// matches reports whether keyword k occurs in lower case text, "^" and "$"
+// anchor k to the start and the end of a word
+func matches(text, k string) bool {
+	start, end := strings.HasPrefix(k, "^"), strings.HasSuffix(k, "$")
+	if !start && !end {
+		return strings.Contains(text, k)
+	}
+	k = strings.TrimSuffix(strings.TrimPrefix(k, "^"), "$")
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		switch {
+		case start && end && word == k,
+			start && !end && strings.HasPrefix(word, k),
+			!start && end && strings.HasSuffix(word, k):
+			return true
+		}
+	}
+	return false
+}
+
+// nothing are words telling that a section is empty
+var nothing = map[string]bool{
+	"нет": true, "нету": true, "ничего": true, "отсутствуют": true,
+	"no": true, "none": true, "nothing": true, "nope": true,
+}
+
+// Empty reports whether text of section s has nothing but its keywords and
+// negations, e.g. "проблем нет" or "Blockers: none"
+func (p Parser) Empty(s Section, text string) bool {
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		word = strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if word == "" || nothing[word] || p.hasKeyword(s, word) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func (p Parser) hasKeyword(s Section, word string) bool {
+	for _, k := range p.Keywords[s] {
+		if matches(word, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// segments splits text into lines and sentences
+func segments(text string) []string {
+	res := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		runes := []rune(line)
+		start := 0
+		for i, r := range runes {
+			end := i == len(runes)-1
+			if !end && !(strings.ContainsRune(".!?", r) && unicode.IsSpace(runes[i+1])) {
+				continue
+			}
+			if s := strings.TrimSpace(string(runes[start : i+1])); s != "" {
+				res = append(res, s)
+			}
+			start = i + 1
+		}
+	}
+	return res
+}
@@ -0,0 +1,175 @@
+package standup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		title string
+		text  string
+		res   Standup
+	}{
+		{
+			"sentences",
+			"Вчера работал над проектом XYZ, закрыл тикеты 456, 89, 289. Сегодня буду работать над тикетом 203. Проблемы: проект не запускается в докере!",
+			Standup{
+				Yesterday: "Вчера работал над проектом XYZ, закрыл тикеты 456, 89, 289.",
+				Today:     "Сегодня буду работать над тикетом 203.",
+				Blockers:  "проект не запускается в докере!",
+			},
+		},
+		{
+			"headings",
+			"@bot\nСделано:\n- верстка\n- тесты\nПланы:\n- деплой\nПроблемы: нет",
+			Standup{
+				Yesterday: "- верстка\n- тесты",
+				Today:     "- деплой",
+				Blockers:  "нет",
+			},
+		},
+		{
+			"markers",
+			"✅ починил логин\n🎯 пишу отчеты\n🚧 жду доступ к серверу",
+			Standup{
+				Yesterday: "починил логин",
+				Today:     "пишу отчеты",
+				Blockers:  "жду доступ к серверу",
+			},
+		},
+		{
+			"one line",
+			"вчера делал отчеты, сегодня делаю графики, проблем нет",
+			Standup{
+				Yesterday: "вчера делал отчеты",
+				Today:     "сегодня делаю графики",
+				Blockers:  "проблем нет",
+			},
+		},
+		{
+			"not a standup",
+			"Я написал стэндап!",
+			Standup{},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.title, func(t *testing.T) {
+			assert.Equal(t, tt.res, Default.Parse(tt.text))
+		})
+	}
+}
+
+func TestIsStandup(t *testing.T) {
+	assert.True(t, Default.IsStandup("вчера делал отчеты, сегодня делаю графики, проблем нет"))
+	assert.True(t, Default.IsStandup("✅ починил логин\n🎯 пишу отчеты\n🚧 жду доступ"))
+	assert.False(t, Default.IsStandup("✅ 🎯 🚧"))
+	assert.False(t, Default.IsStandup("Вчера работал, сегодня буду работать"))
+	assert.True(t, Default.IsStandup("Вчера работал, сегодня буду работать\n🚧 тесты падают"))
+}
+
+func TestMissing(t *testing.T) {
+	assert.Equal(t, []Section{Blockers}, Default.Missing("Вчера работал, сегодня буду работать"))
+	assert.Equal(t, []Section{Yesterday, Today, Blockers}, Default.Missing("✅ 🎯 🚧"))
+	assert.Equal(t, []Section{Today}, Default.Missing("✅ починил логин\nпроблем нет"))
+	assert.Equal(t, []Section{}, Default.Missing("вчера делал отчеты, сегодня делаю графики, проблем нет"))
+}
+
+func TestEmpty(t *testing.T) {
+	assert.True(t, Default.Empty(Blockers, "проблем нет"))
+	assert.True(t, Default.Empty(Blockers, "Проблемы: нет."))
+	assert.True(t, Default.Empty(Blockers, "-"))
+	assert.True(t, Packs.Parser("en").Empty(Blockers, "No blockers"))
+	assert.False(t, Default.Empty(Blockers, "проект не запускается в докере!"))
+	assert.False(t, Default.Empty(Blockers, "нет доступа к серверу"))
+}
+
+// samples are standups every pack must accept, keyed by language code
+var samples = map[string][]string{
+	"ru": {
+		"Вчера работал над проектом XYZ. Сегодня буду работать над тикетом 203. Проблемы: проект не запускается в докере!",
+		"Сделано: верстка\nПланирую: тесты\nТрудности: нет",
+	},
+	"en": {
+		"Yesterday I fixed the login page. Today I will write reports. Problems: none",
+		"Done: layout\nPlans: tests\nBlockers: waiting for server access",
+	},
+}
+
+// notStandups are messages no pack may accept, keyed by language code
+var notStandups = map[string][]string{
+	"ru": {
+		"Всем привет, у кого есть зарядка?",
+	},
+	"en": {
+		"William, our candidate, needs an explanation of the issue",
+		"Undone work is not a problem for Goingsby",
+	},
+}
+
+func TestPacks(t *testing.T) {
+	for code := range Packs {
+		t.Run(code, func(t *testing.T) {
+			assert.NotEmpty(t, samples[code], "pack has no samples")
+			p := Packs.Parser(code)
+			for _, text := range samples[code] {
+				assert.True(t, p.IsStandup(text), text)
+				assert.True(t, p.Parse(text).Complete(), text)
+			}
+			for _, text := range notStandups[code] {
+				assert.False(t, p.IsStandup(text), text)
+			}
+			assert.False(t, p.IsStandup("✅ 🎯 🚧"))
+		})
+	}
+}
+
+func TestAnchoredKeywords(t *testing.T) {
+	assert.True(t, matches("i will write tests", "^will$"))
+	assert.False(t, matches("william writes tests", "^will$"))
+	assert.True(t, matches("plans: tests", "^plan"))
+	assert.False(t, matches("the explanation", "^plan"))
+	assert.True(t, matches("it is undone", "done$"))
+	assert.True(t, matches("undone", "done"))
+	_, err := ParseYAML([]byte("yesterday: {keywords: [^$]}\ntoday: {keywords: [a]}\nblockers: {keywords: [b]}"))
+	assert.Error(t, err)
+}
+
+func TestLoadPacks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packs")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "xx.yaml"), []byte(`
+yesterday:
+  keywords: [Foo]
+today:
+  keywords: [bar]
+blockers:
+  keywords: [baz]
+  markers: [🚧]
+`), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "en.json"),
+		[]byte(`{"yesterday": {"keywords": ["was"]}, "today": {"keywords": ["is"]}, "blockers": {"keywords": ["but"]}}`), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("not a pack"), 0644))
+
+	languages, err := NewLanguages(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"en", "ru", "xx"}, languages.Codes())
+	assert.Equal(t, []string{"foo"}, languages["xx"].Yesterday.Keywords)
+	assert.Equal(t, []string{"was"}, languages["en"].Yesterday.Keywords)
+	assert.Equal(t, Standup{Yesterday: "foo", Today: "bar", Blockers: "baz"}, languages.Parser("xx").Parse("foo\nbar\n🚧 baz"))
+
+	codes, err := languages.ParseCodes("RU, xx")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ru", "xx"}, codes)
+	_, err = languages.ParseCodes("ky")
+	assert.Error(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bad.yml"), []byte("today:\n  keywords: [x]\n"), 0644))
+	_, err = NewLanguages(dir)
+	assert.Error(t, err)
+}
@@ -0,0 +1,540 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/maddevsio/punisher/model"
+)
+
+// Memory is a thread-safe in-memory Store. It mimics MySQL behaviour,
+// including sql.ErrNoRows for missing entries, and forgets everything on exit.
+type Memory struct {
+	mu          sync.RWMutex
+	standups    []model.Standup
+	attachments []model.Attachment
+	interns     []model.Intern
+	groups      map[int64]model.Group
+	daysOff     []model.DayOff
+	absences    []model.Absence
+	punishments []model.Punishment
+	messages    []model.Message
+	lastIDs     map[string]int64
+	// Now stamps standups like NOW() of MySQL, time.Now unless replaced
+	Now func() time.Time
+}
+
+// NewMemory creates an empty in-memory storage
+func NewMemory() *Memory {
+	return &Memory{
+		groups:  map[int64]model.Group{},
+		lastIDs: map[string]int64{},
+		Now:     time.Now,
+	}
+}
+
+// nextID emulates per table AUTO_INCREMENT, must be called under write lock
+func (m *Memory) nextID(table string) int64 {
+	m.lastIDs[table]++
+	return m.lastIDs[table]
+}
+
+// CreateStandup creates standup entry in memory
+func (m *Memory) CreateStandup(s model.Standup) (model.Standup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.ID = m.nextID("standup")
+	s.Created = m.Now().UTC()
+	s.Modified = s.Created
+	m.standups = append(m.standups, s)
+	return s, nil
+}
+
+// UpdateStandup updates standup entry in memory
+func (m *Memory) UpdateStandup(s model.Standup) (model.Standup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.standups {
+		if m.standups[i].ID == s.ID {
+			m.standups[i].Modified = m.Now().UTC()
+			m.standups[i].Username = s.Username
+			m.standups[i].Comment = s.Comment
+			m.standups[i].Yesterday = s.Yesterday
+			m.standups[i].Today = s.Today
+			m.standups[i].Blockers = s.Blockers
+			return m.standups[i], nil
+		}
+	}
+	return model.Standup{}, sql.ErrNoRows
+}
+
+// SelectStandup selects standup entry from memory
+func (m *Memory) SelectStandup(id int64) (model.Standup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.standups {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return model.Standup{}, sql.ErrNoRows
+}
+
+// DeleteStandup deletes standup entry from memory
+func (m *Memory) DeleteStandup(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, s := range m.standups {
+		if s.ID == id {
+			m.standups = append(m.standups[:i], m.standups[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// ListStandups returns array of standup entries from memory
+func (m *Memory) ListStandups() ([]model.Standup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := make([]model.Standup, len(m.standups))
+	copy(items, m.standups)
+	return items, nil
+}
+
+// FindStandups returns standups matching the filter, oldest first
+func (m *Memory) FindStandups(f StandupFilter) ([]model.Standup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := []model.Standup{}
+	for _, s := range m.standups {
+		if f.match(s) {
+			items = append(items, s)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Created.Before(items[j].Created) })
+	if f.Offset >= len(items) {
+		return []model.Standup{}, nil
+	}
+	items = items[f.Offset:]
+	if f.Limit > 0 && f.Limit < len(items) {
+		items = items[:f.Limit]
+	}
+	return items, nil
+}
+
+// LastStandupFor returns last standup for intern
+func (m *Memory) LastStandupFor(username string, groupID int64) (model.Standup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for i := len(m.standups) - 1; i >= 0; i-- {
+		s := m.standups[i]
+		if s.Username == username && s.GroupID == groupID {
+			return s, nil
+		}
+	}
+	return model.Standup{}, sql.ErrNoRows
+}
+
+// FindStandupByMessage finds standup posted as the Telegram message
+func (m *Memory) FindStandupByMessage(chatID int64, messageID int) (model.Standup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.standups {
+		if s.ChatID == chatID && s.MessageID == messageID {
+			return s, nil
+		}
+	}
+	return model.Standup{}, sql.ErrNoRows
+}
+
+// CreateAttachment saves file posted with a standup
+func (m *Memory) CreateAttachment(a model.Attachment) (model.Attachment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a.ID = m.nextID("attachments")
+	m.attachments = append(m.attachments, a)
+	return a, nil
+}
+
+// ListAttachments returns files of a standup in the order they were saved
+func (m *Memory) ListAttachments(standupID int64) ([]model.Attachment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := []model.Attachment{}
+	for _, a := range m.attachments {
+		if a.StandupID == standupID {
+			items = append(items, a)
+		}
+	}
+	return items, nil
+}
+
+// CreateIntern creates intern
+func (m *Memory) CreateIntern(s model.Intern) (model.Intern, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.ID = m.nextID("interns")
+	m.interns = append(m.interns, s)
+	return s, nil
+}
+
+// UpdateIntern updates intern entry in memory
+func (m *Memory) UpdateIntern(s model.Intern) (model.Intern, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.interns {
+		if m.interns[i].ID == s.ID {
+			m.interns[i].Username = s.Username
+			m.interns[i].Lives = s.Lives
+			m.interns[i].UserID = s.UserID
+			m.interns[i].Streak = s.Streak
+			m.interns[i].Checks = s.Checks
+			m.interns[i].OnTime = s.OnTime
+			return m.interns[i], nil
+		}
+	}
+	return model.Intern{}, sql.ErrNoRows
+}
+
+// SelectIntern selects intern entry from memory
+func (m *Memory) SelectIntern(id int64) (model.Intern, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.interns {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return model.Intern{}, sql.ErrNoRows
+}
+
+// FindIntern selects intern entry from memory
+func (m *Memory) FindIntern(name string, groupID int64) (model.Intern, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.interns {
+		if s.Username == name && s.GroupID == groupID {
+			return s, nil
+		}
+	}
+	return model.Intern{}, sql.ErrNoRows
+}
+
+// FindInternByUserID selects intern entry by Telegram user ID
+func (m *Memory) FindInternByUserID(userID int64, groupID int64) (model.Intern, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.interns {
+		if s.UserID == userID && s.GroupID == groupID {
+			return s, nil
+		}
+	}
+	return model.Intern{}, sql.ErrNoRows
+}
+
+// FindInternsByUsername selects entries of the intern in every group
+func (m *Memory) FindInternsByUsername(name string) ([]model.Intern, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := []model.Intern{}
+	for _, s := range m.interns {
+		if s.Username == name {
+			items = append(items, s)
+		}
+	}
+	return items, nil
+}
+
+// DeleteIntern deletes intern entry from memory
+func (m *Memory) DeleteIntern(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, s := range m.interns {
+		if s.ID == id {
+			m.interns = append(m.interns[:i], m.interns[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// ListInterns returns array of intern entries from memory
+func (m *Memory) ListInterns() ([]model.Intern, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := make([]model.Intern, len(m.interns))
+	copy(items, m.interns)
+	return items, nil
+}
+
+// ListGroupInterns returns interns of the group
+func (m *Memory) ListGroupInterns(groupID int64) ([]model.Intern, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := []model.Intern{}
+	for _, i := range m.interns {
+		if i.GroupID == groupID {
+			items = append(items, i)
+		}
+	}
+	return items, nil
+}
+
+// CreateGroup creates group settings entry
+func (m *Memory) CreateGroup(g model.Group) (model.Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.groups[g.ID]; ok {
+		return g, fmt.Errorf("duplicate entry %d for key PRIMARY", g.ID)
+	}
+	m.groups[g.ID] = g
+	return g, nil
+}
+
+// UpdateGroup updates group settings entry
+func (m *Memory) UpdateGroup(g model.Group) (model.Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.groups[g.ID]; !ok {
+		return model.Group{}, sql.ErrNoRows
+	}
+	m.groups[g.ID] = g
+	return g, nil
+}
+
+// SelectGroup selects group settings entry
+func (m *Memory) SelectGroup(id int64) (model.Group, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	g, ok := m.groups[id]
+	if !ok {
+		return model.Group{}, sql.ErrNoRows
+	}
+	return g, nil
+}
+
+// ListGroups lists unique groups the bot is added to
+func (m *Memory) ListGroups() ([]int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	seen := map[int64]bool{}
+	groups := []int64{}
+	for _, i := range m.interns {
+		if !seen[i.GroupID] {
+			seen[i.GroupID] = true
+			groups = append(groups, i.GroupID)
+		}
+	}
+	for id := range m.groups {
+		if !seen[id] {
+			seen[id] = true
+			groups = append(groups, id)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i] < groups[j] })
+	return groups, nil
+}
+
+// CreateDayOff creates one-off day off of a group
+func (m *Memory) CreateDayOff(d model.DayOff) (model.DayOff, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d.ID = m.nextID("daysoff")
+	m.daysOff = append(m.daysOff, d)
+	return d, nil
+}
+
+// DeleteDayOff deletes day off entry
+func (m *Memory) DeleteDayOff(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, d := range m.daysOff {
+		if d.ID == id {
+			m.daysOff = append(m.daysOff[:i], m.daysOff[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// ListDaysOff returns days off of a group ordered by date
+func (m *Memory) ListDaysOff(groupID int64) ([]model.DayOff, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := []model.DayOff{}
+	for _, d := range m.daysOff {
+		if d.GroupID == groupID {
+			items = append(items, d)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Date.Before(items[j].Date) })
+	return items, nil
+}
+
+// CreateAbsence creates absence of an intern
+func (m *Memory) CreateAbsence(a model.Absence) (model.Absence, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a.ID = m.nextID("absences")
+	m.absences = append(m.absences, a)
+	return a, nil
+}
+
+// UpdateAbsence updates absence entry
+func (m *Memory) UpdateAbsence(a model.Absence) (model.Absence, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.absences {
+		if m.absences[i].ID == a.ID {
+			m.absences[i].Kind = a.Kind
+			m.absences[i].Since = a.Since
+			m.absences[i].Until = a.Until
+			return m.absences[i], nil
+		}
+	}
+	return model.Absence{}, sql.ErrNoRows
+}
+
+// DeleteAbsence deletes absence entry
+func (m *Memory) DeleteAbsence(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, a := range m.absences {
+		if a.ID == id {
+			m.absences = append(m.absences[:i], m.absences[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// ListAbsences returns absences of a group ordered by start date
+func (m *Memory) ListAbsences(groupID int64) ([]model.Absence, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := []model.Absence{}
+	for _, a := range m.absences {
+		if a.GroupID == groupID {
+			items = append(items, a)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Since.Before(items[j].Since) })
+	return items, nil
+}
+
+// CreatePunishment creates punishments ledger entry
+func (m *Memory) CreatePunishment(p model.Punishment) (model.Punishment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p.ID = m.nextID("punishments")
+	m.punishments = append(m.punishments, p)
+	return p, nil
+}
+
+// UpdatePunishment updates status, proof and progress of punishment
+func (m *Memory) UpdatePunishment(p model.Punishment) (model.Punishment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.punishments {
+		if m.punishments[i].ID == p.ID {
+			m.punishments[i].Status = p.Status
+			m.punishments[i].ProofMessageID = p.ProofMessageID
+			m.punishments[i].Done = p.Done
+			return m.punishments[i], nil
+		}
+	}
+	return model.Punishment{}, sql.ErrNoRows
+}
+
+// SelectPunishment selects punishments ledger entry
+func (m *Memory) SelectPunishment(id int64) (model.Punishment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, p := range m.punishments {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return model.Punishment{}, sql.ErrNoRows
+}
+
+// FindPunishmentByMessage finds punishment announced by the bot's message
+func (m *Memory) FindPunishmentByMessage(groupID int64, messageID int) (model.Punishment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, p := range m.punishments {
+		if p.GroupID == groupID && p.MessageID == messageID {
+			return p, nil
+		}
+	}
+	return model.Punishment{}, sql.ErrNoRows
+}
+
+// ListPunishments returns punishments of a group ordered by issue time
+func (m *Memory) ListPunishments(groupID int64) ([]model.Punishment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := []model.Punishment{}
+	for _, p := range m.punishments {
+		if p.GroupID == groupID {
+			items = append(items, p)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Issued.Before(items[j].Issued) })
+	return items, nil
+}
+
+// SetMessage creates or replaces message override of a group
+func (m *Memory) SetMessage(msg model.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, item := range m.messages {
+		if item.GroupID == msg.GroupID && item.Key == msg.Key {
+			m.messages[i] = msg
+			return nil
+		}
+	}
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// DeleteMessage deletes message override of a group
+func (m *Memory) DeleteMessage(groupID int64, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, item := range m.messages {
+		if item.GroupID == groupID && item.Key == key {
+			m.messages = append(m.messages[:i], m.messages[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// ListMessages returns message overrides of a group ordered by key
+func (m *Memory) ListMessages(groupID int64) ([]model.Message, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := []model.Message{}
+	for _, item := range m.messages {
+		if item.GroupID == groupID {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	return items, nil
+}
+
+// Ping always succeeds
+func (m *Memory) Ping() error {
+	return nil
+}
+
+// SchemaVersion is always the latest, memory has no migrations
+func (m *Memory) SchemaVersion() (int64, error) {
+	return SchemaVersion, nil
+}
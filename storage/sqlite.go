@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	// This line is must for working SQLite database
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteMigrations mirrors migrations/*.sql in SQLite dialect. Entry N is
+// goose version N+1, so a new MySQL migration needs a matching entry here
+// and a bump of SchemaVersion.
+var sqliteMigrations = []string{
+	// 00001_standup.sql
+	"CREATE TABLE `standup` (" +
+		"`id` INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, " +
+		"`created` DATETIME NOT NULL, " +
+		"`modified` DATETIME NOT NULL, " +
+		"`username` VARCHAR(255) NOT NULL, " +
+		"`comment` VARCHAR(255) NOT NULL);" +
+		"CREATE INDEX `standup_created_username` ON `standup` (`created`, `username`);",
+	// 00002_lives.sql
+	"CREATE TABLE `interns` (" +
+		"`id` INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, " +
+		"`username` VARCHAR(255) NOT NULL, " +
+		"`lives` INTEGER NOT NULL);",
+	// 00003_groupid.sql
+	"ALTER TABLE `standup` ADD `groupid` BIGINT NOT NULL DEFAULT 0;" +
+		"ALTER TABLE `interns` ADD `groupid` BIGINT NOT NULL DEFAULT 0;",
+	// 00004_userid.sql
+	"ALTER TABLE `interns` ADD `userid` BIGINT NOT NULL DEFAULT 0;",
+	// 00005_groups.sql
+	"CREATE TABLE `groups` (" +
+		"`id` BIGINT NOT NULL PRIMARY KEY, " +
+		"`punishtime` VARCHAR(5) NOT NULL, " +
+		"`punishmenttype` VARCHAR(255) NOT NULL, " +
+		"`notifymentors` BOOLEAN NOT NULL, " +
+		"`mentorschat` BIGINT NOT NULL, " +
+		"`lives` INTEGER NOT NULL);",
+	// 00006_timezone.sql
+	"ALTER TABLE `groups` ADD `timezone` VARCHAR(64) NOT NULL DEFAULT '';",
+	// 00007_calendar.sql
+	"ALTER TABLE `groups` ADD `workdays` VARCHAR(32) NOT NULL DEFAULT '';" +
+		"CREATE TABLE `daysoff` (" +
+		"`id` INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, " +
+		"`groupid` BIGINT NOT NULL, " +
+		"`date` DATE NOT NULL, " +
+		"`reason` VARCHAR(255) NOT NULL);" +
+		"CREATE INDEX `daysoff_groupid_date` ON `daysoff` (`groupid`, `date`);",
+	// 00008_absences.sql
+	"CREATE TABLE `absences` (" +
+		"`id` INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, " +
+		"`internid` INTEGER NOT NULL, " +
+		"`groupid` BIGINT NOT NULL, " +
+		"`kind` VARCHAR(16) NOT NULL, " +
+		"`since` DATE NOT NULL, " +
+		"`until` DATE NOT NULL);" +
+		"CREATE INDEX `absences_groupid_until` ON `absences` (`groupid`, `until`);",
+	// 00009_reminders.sql
+	"ALTER TABLE `groups` ADD `reminders` VARCHAR(64) NOT NULL DEFAULT '';" +
+		"ALTER TABLE `groups` ADD `remindprivately` BOOLEAN NOT NULL DEFAULT FALSE;",
+	// 00010_punishments.sql
+	"CREATE TABLE `punishments` (" +
+		"`id` INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, " +
+		"`internid` INTEGER NOT NULL, " +
+		"`groupid` BIGINT NOT NULL, " +
+		"`type` VARCHAR(64) NOT NULL, " +
+		"`amount` INTEGER NOT NULL, " +
+		"`issued` DATETIME NOT NULL, " +
+		"`status` VARCHAR(16) NOT NULL, " +
+		"`messageid` INTEGER NOT NULL, " +
+		"`proofmessageid` INTEGER NOT NULL);" +
+		"CREATE INDEX `punishments_groupid_messageid` ON `punishments` (`groupid`, `messageid`);",
+	// 00011_debts.sql
+	"ALTER TABLE `punishments` ADD `done` INTEGER NOT NULL DEFAULT 0;",
+	// 00012_streaks.sql
+	"ALTER TABLE `interns` ADD `streak` INTEGER NOT NULL DEFAULT 0;" +
+		"ALTER TABLE `interns` ADD `checks` INTEGER NOT NULL DEFAULT 0;" +
+		"ALTER TABLE `interns` ADD `ontime` INTEGER NOT NULL DEFAULT 0;" +
+		"ALTER TABLE `groups` ADD `streakbonus` INTEGER NOT NULL DEFAULT 0;" +
+		"ALTER TABLE `groups` ADD `maxlives` INTEGER NOT NULL DEFAULT 0;",
+	// 00013_sections.sql
+	"ALTER TABLE `standup` ADD `yesterday` TEXT NOT NULL DEFAULT '';" +
+		"ALTER TABLE `standup` ADD `today` TEXT NOT NULL DEFAULT '';" +
+		"ALTER TABLE `standup` ADD `blockers` TEXT NOT NULL DEFAULT '';",
+	// 00014_languages.sql
+	"ALTER TABLE `groups` ADD `languages` VARCHAR(64) NOT NULL DEFAULT '';",
+	// 00015_messages.sql
+	"ALTER TABLE `groups` ADD `locale` VARCHAR(8) NOT NULL DEFAULT '';" +
+		"CREATE TABLE `messages` (" +
+		"`groupid` BIGINT NOT NULL, " +
+		"`msgkey` VARCHAR(64) NOT NULL, " +
+		"`text` TEXT NOT NULL, " +
+		"PRIMARY KEY (`groupid`, `msgkey`));",
+	// 00016_standup_messages.sql
+	"ALTER TABLE `standup` ADD `chatid` BIGINT NOT NULL DEFAULT 0;" +
+		"ALTER TABLE `standup` ADD `messageid` INTEGER NOT NULL DEFAULT 0;" +
+		"CREATE INDEX `standup_chatid_messageid` ON `standup` (`chatid`, `messageid`);",
+	// 00017_attachments.sql, SQLite does not limit VARCHAR length, so
+	// standup comments need no change
+	"CREATE TABLE `attachments` (" +
+		"`id` INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, " +
+		"`standupid` INTEGER NOT NULL, " +
+		"`type` VARCHAR(16) NOT NULL, " +
+		"`fileid` VARCHAR(255) NOT NULL);" +
+		"CREATE INDEX `attachments_standupid` ON `attachments` (`standupid`);",
+	// 00018_standup_groups.sql
+	"ALTER TABLE `groups` ADD `sharedstandups` BOOLEAN NOT NULL DEFAULT FALSE;" +
+		"UPDATE `standup` SET `groupid` = `chatid` WHERE `groupid` = 0 AND `chatid` <> 0;" +
+		"UPDATE `standup` SET `groupid` = (" +
+		"SELECT MIN(`groupid`) FROM `interns` WHERE `interns`.`username` = `standup`.`username`" +
+		") WHERE `groupid` = 0 AND (" +
+		"SELECT COUNT(DISTINCT `groupid`) FROM `interns` WHERE `interns`.`username` = `standup`.`username`" +
+		") = 1;" +
+		"CREATE INDEX `standup_username_groupid` ON `standup` (`username`, `groupid`);",
+	// 00019_group_defaults.sql, SQLite can not change a column, so the
+	// table is rebuilt
+	"CREATE TABLE `groups_new` (" +
+		"`id` BIGINT NOT NULL PRIMARY KEY, " +
+		"`punishtime` VARCHAR(5) NOT NULL, " +
+		"`punishmenttype` VARCHAR(255) NOT NULL, " +
+		"`notifymentors` BOOLEAN NULL DEFAULT NULL, " +
+		"`mentorschat` BIGINT NOT NULL, " +
+		"`lives` INTEGER NOT NULL, " +
+		"`timezone` VARCHAR(64) NOT NULL DEFAULT '', " +
+		"`workdays` VARCHAR(32) NOT NULL DEFAULT '', " +
+		"`reminders` VARCHAR(64) NOT NULL DEFAULT '', " +
+		"`remindprivately` BOOLEAN NULL DEFAULT NULL, " +
+		"`streakbonus` INTEGER NOT NULL DEFAULT 0, " +
+		"`maxlives` INTEGER NOT NULL DEFAULT 0, " +
+		"`languages` VARCHAR(64) NOT NULL DEFAULT '', " +
+		"`locale` VARCHAR(8) NOT NULL DEFAULT '', " +
+		"`sharedstandups` BOOLEAN NULL DEFAULT NULL);" +
+		"INSERT INTO `groups_new` SELECT * FROM `groups`;" +
+		"DROP TABLE `groups`;" +
+		"ALTER TABLE `groups_new` RENAME TO `groups`;",
+}
+
+// SQLite provides api for work with embedded sqlite database.
+// It shares all queries with MySQL, only connection setup and schema differ.
+type SQLite struct {
+	*sqlDB
+}
+
+// NewSQLite opens (or creates) sqlite database at path and brings its schema
+// up to date. Use ":memory:" for a throwaway database.
+func NewSQLite(path string) (*SQLite, error) {
+	conn, err := sqlx.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	// every connection to ":memory:" is a separate database, and sqlite
+	// does not like concurrent writers anyway
+	conn.SetMaxOpenConns(1)
+	if err := migrateSQLite(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &SQLite{&sqlDB{conn}}, nil
+}
+
+// migrateSQLite applies missing sqliteMigrations and records them in the same
+// version table goose uses for MySQL
+func migrateSQLite(conn *sqlx.DB) error {
+	_, err := conn.Exec("CREATE TABLE IF NOT EXISTS `goose_db_version` (" +
+		"`id` INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, " +
+		"`version_id` BIGINT NOT NULL, " +
+		"`is_applied` BOOLEAN NOT NULL, " +
+		"`tstamp` DATETIME)")
+	if err != nil {
+		return err
+	}
+	version, err := (&sqlDB{conn}).SchemaVersion()
+	if err != nil {
+		return err
+	}
+	for v := version; v < int64(len(sqliteMigrations)); v++ {
+		tx, err := conn.Beginx()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqliteMigrations[v]); err != nil {
+			tx.Rollback()
+			return err
+		}
+		_, err = tx.Exec(
+			"INSERT INTO `goose_db_version` (version_id, is_applied, tstamp) VALUES (?, 1, ?)",
+			v+1, time.Now().UTC(),
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
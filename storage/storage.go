@@ -1,6 +1,10 @@
 package storage
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	// This line is must for working MySQL database
@@ -12,24 +16,165 @@ import (
 	"github.com/maddevsio/punisher/model"
 )
 
+const (
+	mysqlScheme  = "mysql://"
+	sqliteScheme = "sqlite://"
+	memoryScheme = "memory://"
+)
+
+// SchemaVersion is the goose version of the latest migration in migrations/
+const SchemaVersion = 19
+
+// pingTimeout limits Ping of unreachable databases
+const pingTimeout = 5 * time.Second
+
+// Store is the set of operations the bot needs from a storage backend
+type Store interface {
+	CreateStandup(model.Standup) (model.Standup, error)
+	UpdateStandup(model.Standup) (model.Standup, error)
+	SelectStandup(int64) (model.Standup, error)
+	DeleteStandup(int64) error
+	ListStandups() ([]model.Standup, error)
+	FindStandups(StandupFilter) ([]model.Standup, error)
+	LastStandupFor(username string, groupID int64) (model.Standup, error)
+	FindStandupByMessage(chatID int64, messageID int) (model.Standup, error)
+
+	CreateAttachment(model.Attachment) (model.Attachment, error)
+	ListAttachments(standupID int64) ([]model.Attachment, error)
+
+	CreateIntern(model.Intern) (model.Intern, error)
+	UpdateIntern(model.Intern) (model.Intern, error)
+	SelectIntern(int64) (model.Intern, error)
+	FindIntern(name string, groupID int64) (model.Intern, error)
+	FindInternByUserID(userID int64, groupID int64) (model.Intern, error)
+	FindInternsByUsername(name string) ([]model.Intern, error)
+	DeleteIntern(int64) error
+	ListInterns() ([]model.Intern, error)
+	ListGroupInterns(groupID int64) ([]model.Intern, error)
+
+	CreateGroup(model.Group) (model.Group, error)
+	UpdateGroup(model.Group) (model.Group, error)
+	SelectGroup(int64) (model.Group, error)
+	ListGroups() ([]int64, error)
+
+	CreateDayOff(model.DayOff) (model.DayOff, error)
+	DeleteDayOff(int64) error
+	ListDaysOff(groupID int64) ([]model.DayOff, error)
+
+	CreateAbsence(model.Absence) (model.Absence, error)
+	UpdateAbsence(model.Absence) (model.Absence, error)
+	DeleteAbsence(int64) error
+	ListAbsences(groupID int64) ([]model.Absence, error)
+
+	CreatePunishment(model.Punishment) (model.Punishment, error)
+	UpdatePunishment(model.Punishment) (model.Punishment, error)
+	SelectPunishment(int64) (model.Punishment, error)
+	FindPunishmentByMessage(groupID int64, messageID int) (model.Punishment, error)
+	ListPunishments(groupID int64) ([]model.Punishment, error)
+
+	SetMessage(model.Message) error
+	DeleteMessage(groupID int64, key string) error
+	ListMessages(groupID int64) ([]model.Message, error)
+
+	// Ping checks the database is reachable
+	Ping() error
+	// SchemaVersion returns the latest applied migration
+	SchemaVersion() (int64, error)
+}
+
+// StandupFilter selects standups, zero fields match any standup
+type StandupFilter struct {
+	Username string
+	GroupID  int64
+	// Since is inclusive, Until is exclusive
+	Since time.Time
+	Until time.Time
+	// Limit of 0 returns all standups from Offset
+	Limit  int
+	Offset int
+}
+
+func (f StandupFilter) match(s model.Standup) bool {
+	return (f.Username == "" || s.Username == f.Username) &&
+		(f.GroupID == 0 || s.GroupID == f.GroupID) &&
+		(f.Since.IsZero() || !s.Created.Before(f.Since)) &&
+		(f.Until.IsZero() || s.Created.Before(f.Until))
+}
+
+// New creates a storage backend chosen by the scheme of DatabaseURL:
+// "sqlite://path/to/file.db" (or "sqlite://:memory:") opens embedded SQLite,
+// "memory://" keeps everything in process memory (demo mode),
+// anything else is treated as a MySQL DSN, optionally prefixed with "mysql://"
+func New(c *config.BotConfig) (Store, error) {
+	switch {
+	case strings.HasPrefix(c.DatabaseURL, memoryScheme):
+		return NewMemory(), nil
+	case strings.HasPrefix(c.DatabaseURL, sqliteScheme):
+		return NewSQLite(strings.TrimPrefix(c.DatabaseURL, sqliteScheme))
+	default:
+		return NewMySQL(c)
+	}
+}
+
+// sqlDB implements Store on top of any database/sql driver that
+// understands MySQL flavoured queries (MySQL itself and SQLite)
+type sqlDB struct {
+	conn *sqlx.DB
+}
+
 // MySQL provides api for work with mysql database
 type MySQL struct {
-	conn *sqlx.DB
+	*sqlDB
 }
 
-// NewMySQL creates a new instance of database API
+// NewMySQL connects to MySQL, migrations are applied by goose
 func NewMySQL(c *config.BotConfig) (*MySQL, error) {
-	conn, _ := sqlx.Open("mysql", c.DatabaseURL)
-	m := &MySQL{}
-	m.conn = conn
+	conn, err := sqlx.Open("mysql", strings.TrimPrefix(c.DatabaseURL, mysqlScheme))
+	if err != nil {
+		return nil, err
+	}
+	m := &MySQL{&sqlDB{conn}}
+	if err := m.Ping(); err != nil {
+		conn.Close()
+		return nil, err
+	}
 	return m, nil
 }
 
+// CheckSchema fails unless db has exactly the migrations this build expects
+func CheckSchema(db Store) error {
+	v, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if v != SchemaVersion {
+		return fmt.Errorf("schema version is %d, expected %d", v, SchemaVersion)
+	}
+	return nil
+}
+
+// Ping checks the database is reachable
+func (m *sqlDB) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	return m.conn.PingContext(ctx)
+}
+
+// SchemaVersion returns the latest applied migration. Like goose, it takes
+// the newest row of every version, "goose down" adds a row that is not
+// applied.
+func (m *sqlDB) SchemaVersion() (int64, error) {
+	var version int64
+	err := m.conn.Get(&version, "SELECT COALESCE(MAX(v.version_id), 0) FROM `goose_db_version` v "+
+		"WHERE v.is_applied AND v.id = (SELECT MAX(id) FROM `goose_db_version` WHERE version_id = v.version_id)")
+	return version, err
+}
+
 // CreateStandup creates standup entry in database
-func (m *MySQL) CreateStandup(s model.Standup) (model.Standup, error) {
+func (m *sqlDB) CreateStandup(s model.Standup) (model.Standup, error) {
 	res, err := m.conn.Exec(
-		"INSERT INTO `standup` (created, modified, username, comment, groupid) VALUES (?, ?, ?, ?, ?)",
-		time.Now().UTC(), time.Now().UTC(), s.Username, s.Comment, s.GroupID,
+		"INSERT INTO `standup` (created, modified, username, comment, groupid, yesterday, today, blockers, chatid, messageid) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		time.Now().UTC(), time.Now().UTC(), s.Username, s.Comment, s.GroupID, s.Yesterday, s.Today, s.Blockers, s.ChatID, s.MessageID,
 	)
 	if err != nil {
 		return s, err
@@ -40,95 +185,356 @@ func (m *MySQL) CreateStandup(s model.Standup) (model.Standup, error) {
 }
 
 // UpdateStandup updates standup entry in database
-func (m *MySQL) UpdateStandup(s model.Standup) (model.Standup, error) {
-	var i model.Standup
-	m.conn.Exec(
-		"UPDATE `standup` SET modified=?, username=?, comment=? WHERE id=?",
-		time.Now().UTC(), s.Username, s.Comment, s.ID,
+func (m *sqlDB) UpdateStandup(s model.Standup) (model.Standup, error) {
+	_, err := m.conn.Exec(
+		"UPDATE `standup` SET modified=?, username=?, comment=?, yesterday=?, today=?, blockers=? WHERE id=?",
+		time.Now().UTC(), s.Username, s.Comment, s.Yesterday, s.Today, s.Blockers, s.ID,
 	)
-	err := m.conn.Get(&i, "SELECT * FROM `standup` WHERE id=?", s.ID)
-	return i, err
+	if err != nil {
+		return s, err
+	}
+	return m.SelectStandup(s.ID)
 }
 
 // SelectStandup selects standup entry from database
-func (m *MySQL) SelectStandup(id int64) (model.Standup, error) {
+func (m *sqlDB) SelectStandup(id int64) (model.Standup, error) {
 	var s model.Standup
 	err := m.conn.Get(&s, "SELECT * FROM `standup` WHERE id=?", id)
 	return s, err
 }
 
 // DeleteStandup deletes standup entry from database
-func (m *MySQL) DeleteStandup(id int64) error {
+func (m *sqlDB) DeleteStandup(id int64) error {
 	_, err := m.conn.Exec("DELETE FROM `standup` WHERE id=?", id)
 	return err
 }
 
 // ListStandups returns array of standup entries from database
-func (m *MySQL) ListStandups() ([]model.Standup, error) {
+func (m *sqlDB) ListStandups() ([]model.Standup, error) {
 	items := []model.Standup{}
 	err := m.conn.Select(&items, "SELECT * FROM `standup`")
 	return items, err
 }
 
-//LastStandupFor returns last standup for intern
-func (m *MySQL) LastStandupFor(username string, groupID int64) (model.Standup, error) {
+// FindStandups returns standups matching the filter, oldest first
+func (m *sqlDB) FindStandups(f StandupFilter) ([]model.Standup, error) {
+	where := []string{"1=1"}
+	args := []interface{}{}
+	if f.Username != "" {
+		where = append(where, "username=?")
+		args = append(args, f.Username)
+	}
+	if f.GroupID != 0 {
+		where = append(where, "groupid=?")
+		args = append(args, f.GroupID)
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "created>=?")
+		args = append(args, f.Since.UTC())
+	}
+	if !f.Until.IsZero() {
+		where = append(where, "created<?")
+		args = append(args, f.Until.UTC())
+	}
+	query := "SELECT * FROM `standup` WHERE " + strings.Join(where, " AND ") + " ORDER BY created, id"
+	if f.Limit > 0 || f.Offset > 0 {
+		limit := int64(f.Limit)
+		if limit == 0 {
+			// neither MySQL nor SQLite has OFFSET without LIMIT
+			limit = math.MaxInt64
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, f.Offset)
+	}
+	items := []model.Standup{}
+	err := m.conn.Select(&items, query, args...)
+	return items, err
+}
+
+// LastStandupFor returns last standup for intern
+func (m *sqlDB) LastStandupFor(username string, groupID int64) (model.Standup, error) {
 	var standup model.Standup
 	err := m.conn.Get(&standup, "SELECT * FROM `standup` WHERE username=? and groupid=? ORDER BY id DESC LIMIT 1", username, groupID)
 	return standup, err
 }
 
+// FindStandupByMessage finds standup posted as the Telegram message
+func (m *sqlDB) FindStandupByMessage(chatID int64, messageID int) (model.Standup, error) {
+	var standup model.Standup
+	err := m.conn.Get(&standup, "SELECT * FROM `standup` WHERE chatid=? AND messageid=?", chatID, messageID)
+	return standup, err
+}
+
+// CreateAttachment saves file posted with a standup
+func (m *sqlDB) CreateAttachment(a model.Attachment) (model.Attachment, error) {
+	res, err := m.conn.Exec(
+		"INSERT INTO `attachments` (standupid, type, fileid) VALUES (?, ?, ?)",
+		a.StandupID, a.Type, a.FileID,
+	)
+	if err != nil {
+		return a, err
+	}
+	id, _ := res.LastInsertId()
+	a.ID = id
+	return a, nil
+}
+
+// ListAttachments returns files of a standup in the order they were saved
+func (m *sqlDB) ListAttachments(standupID int64) ([]model.Attachment, error) {
+	items := []model.Attachment{}
+	err := m.conn.Select(&items, "SELECT * FROM `attachments` WHERE standupid=? ORDER BY id", standupID)
+	return items, err
+}
+
 // CreateIntern creates intern
-func (m *MySQL) CreateIntern(s model.Intern) (model.Intern, error) {
-	res, _ := m.conn.Exec(
-		"INSERT INTO `interns` (username, lives, groupid) VALUES (?, ?, ?)",
-		s.Username, s.Lives, s.GroupID,
+func (m *sqlDB) CreateIntern(s model.Intern) (model.Intern, error) {
+	res, err := m.conn.Exec(
+		"INSERT INTO `interns` (username, lives, groupid, userid, streak, checks, ontime) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		s.Username, s.Lives, s.GroupID, s.UserID, s.Streak, s.Checks, s.OnTime,
 	)
+	if err != nil {
+		return s, err
+	}
 	id, _ := res.LastInsertId()
 	s.ID = id
 	return s, nil
 }
 
 // UpdateIntern updates intern entry in database
-func (m *MySQL) UpdateIntern(s model.Intern) (model.Intern, error) {
-	var i model.Intern
-	m.conn.Exec(
-		"UPDATE `interns` SET username=?, lives=? WHERE id=?",
-		s.Username, s.Lives, s.ID,
+func (m *sqlDB) UpdateIntern(s model.Intern) (model.Intern, error) {
+	_, err := m.conn.Exec(
+		"UPDATE `interns` SET username=?, lives=?, userid=?, streak=?, checks=?, ontime=? WHERE id=?",
+		s.Username, s.Lives, s.UserID, s.Streak, s.Checks, s.OnTime, s.ID,
 	)
-	err := m.conn.Get(&i, "SELECT * FROM `interns` WHERE id=?", s.ID)
-	return i, err
+	if err != nil {
+		return s, err
+	}
+	return m.SelectIntern(s.ID)
 }
 
 // SelectIntern selects intern entry from database
-func (m *MySQL) SelectIntern(id int64) (model.Intern, error) {
+func (m *sqlDB) SelectIntern(id int64) (model.Intern, error) {
 	var s model.Intern
 	err := m.conn.Get(&s, "SELECT * FROM `interns` WHERE id=?", id)
 	return s, err
 }
 
 // FindIntern selects intern entry from database
-func (m *MySQL) FindIntern(name string, groupID int64) (model.Intern, error) {
+func (m *sqlDB) FindIntern(name string, groupID int64) (model.Intern, error) {
 	var s model.Intern
 	err := m.conn.Get(&s, "SELECT * FROM `interns` WHERE username=? and groupid=?", name, groupID)
 	return s, err
 }
 
+// FindInternByUserID selects intern entry by Telegram user ID
+func (m *sqlDB) FindInternByUserID(userID int64, groupID int64) (model.Intern, error) {
+	var s model.Intern
+	err := m.conn.Get(&s, "SELECT * FROM `interns` WHERE userid=? and groupid=?", userID, groupID)
+	return s, err
+}
+
+// FindInternsByUsername selects entries of the intern in every group
+func (m *sqlDB) FindInternsByUsername(name string) ([]model.Intern, error) {
+	items := []model.Intern{}
+	err := m.conn.Select(&items, "SELECT * FROM `interns` WHERE username=?", name)
+	return items, err
+}
+
 // DeleteIntern deletes intern entry from database
-func (m *MySQL) DeleteIntern(id int64) error {
+func (m *sqlDB) DeleteIntern(id int64) error {
 	_, err := m.conn.Exec("DELETE FROM `interns` WHERE id=?", id)
 	return err
 }
 
 // ListInterns returns array of intern entries from database
-func (m *MySQL) ListInterns() ([]model.Intern, error) {
+func (m *sqlDB) ListInterns() ([]model.Intern, error) {
 	items := []model.Intern{}
 	err := m.conn.Select(&items, "SELECT * FROM `interns`")
 	return items, err
 }
 
-//ListGroups lists unique groups the bot is added to
-func (m *MySQL) ListGroups() ([]int64, error) {
+// ListGroupInterns returns interns of the group
+func (m *sqlDB) ListGroupInterns(groupID int64) ([]model.Intern, error) {
+	items := []model.Intern{}
+	err := m.conn.Select(&items, "SELECT * FROM `interns` WHERE groupid=?", groupID)
+	return items, err
+}
+
+// CreateGroup creates group settings entry
+func (m *sqlDB) CreateGroup(g model.Group) (model.Group, error) {
+	_, err := m.conn.Exec(
+		"INSERT INTO `groups` (id, punishtime, punishmenttype, notifymentors, mentorschat, lives, timezone, workdays, reminders, remindprivately, streakbonus, maxlives, languages, locale, sharedstandups) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		g.ID, g.PunishTime, g.PunishmentType, g.NotifyMentors, g.MentorsChat, g.Lives, g.Timezone, g.Workdays, g.Reminders, g.RemindPrivately, g.StreakBonus, g.MaxLives, g.Languages, g.Locale, g.SharedStandups,
+	)
+	return g, err
+}
+
+// UpdateGroup updates group settings entry
+func (m *sqlDB) UpdateGroup(g model.Group) (model.Group, error) {
+	_, err := m.conn.Exec(
+		"UPDATE `groups` SET punishtime=?, punishmenttype=?, notifymentors=?, mentorschat=?, lives=?, timezone=?, workdays=?, reminders=?, remindprivately=?, streakbonus=?, maxlives=?, languages=?, locale=?, sharedstandups=? WHERE id=?",
+		g.PunishTime, g.PunishmentType, g.NotifyMentors, g.MentorsChat, g.Lives, g.Timezone, g.Workdays, g.Reminders, g.RemindPrivately, g.StreakBonus, g.MaxLives, g.Languages, g.Locale, g.SharedStandups, g.ID,
+	)
+	if err != nil {
+		return g, err
+	}
+	return m.SelectGroup(g.ID)
+}
+
+// SelectGroup selects group settings entry
+func (m *sqlDB) SelectGroup(id int64) (model.Group, error) {
+	var g model.Group
+	err := m.conn.Get(&g, "SELECT * FROM `groups` WHERE id=?", id)
+	return g, err
+}
+
+// ListGroups lists unique groups the bot is added to
+func (m *sqlDB) ListGroups() ([]int64, error) {
 	groups := []int64{}
-	err := m.conn.Select(&groups, "SELECT distinct groupid FROM `interns`")
+	err := m.conn.Select(&groups, "SELECT groupid FROM `interns` UNION SELECT id FROM `groups`")
 	return groups, err
 }
+
+// CreateDayOff creates one-off day off of a group
+func (m *sqlDB) CreateDayOff(d model.DayOff) (model.DayOff, error) {
+	res, err := m.conn.Exec(
+		"INSERT INTO `daysoff` (groupid, date, reason) VALUES (?, ?, ?)",
+		d.GroupID, d.Date, d.Reason,
+	)
+	if err != nil {
+		return d, err
+	}
+	id, _ := res.LastInsertId()
+	d.ID = id
+	return d, nil
+}
+
+// DeleteDayOff deletes day off entry
+func (m *sqlDB) DeleteDayOff(id int64) error {
+	_, err := m.conn.Exec("DELETE FROM `daysoff` WHERE id=?", id)
+	return err
+}
+
+// ListDaysOff returns days off of a group ordered by date
+func (m *sqlDB) ListDaysOff(groupID int64) ([]model.DayOff, error) {
+	items := []model.DayOff{}
+	err := m.conn.Select(&items, "SELECT * FROM `daysoff` WHERE groupid=? ORDER BY date", groupID)
+	return items, err
+}
+
+// CreateAbsence creates absence of an intern
+func (m *sqlDB) CreateAbsence(a model.Absence) (model.Absence, error) {
+	res, err := m.conn.Exec(
+		"INSERT INTO `absences` (internid, groupid, kind, since, until) VALUES (?, ?, ?, ?, ?)",
+		a.InternID, a.GroupID, a.Kind, a.Since, a.Until,
+	)
+	if err != nil {
+		return a, err
+	}
+	id, _ := res.LastInsertId()
+	a.ID = id
+	return a, nil
+}
+
+// UpdateAbsence updates absence entry
+func (m *sqlDB) UpdateAbsence(a model.Absence) (model.Absence, error) {
+	_, err := m.conn.Exec(
+		"UPDATE `absences` SET kind=?, since=?, until=? WHERE id=?",
+		a.Kind, a.Since, a.Until, a.ID,
+	)
+	return a, err
+}
+
+// DeleteAbsence deletes absence entry
+func (m *sqlDB) DeleteAbsence(id int64) error {
+	_, err := m.conn.Exec("DELETE FROM `absences` WHERE id=?", id)
+	return err
+}
+
+// ListAbsences returns absences of a group ordered by start date
+func (m *sqlDB) ListAbsences(groupID int64) ([]model.Absence, error) {
+	items := []model.Absence{}
+	err := m.conn.Select(&items, "SELECT * FROM `absences` WHERE groupid=? ORDER BY since, id", groupID)
+	return items, err
+}
+
+// CreatePunishment creates punishments ledger entry
+func (m *sqlDB) CreatePunishment(p model.Punishment) (model.Punishment, error) {
+	res, err := m.conn.Exec(
+		"INSERT INTO `punishments` (internid, groupid, type, amount, issued, status, messageid, proofmessageid) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		p.InternID, p.GroupID, p.Type, p.Amount, p.Issued, p.Status, p.MessageID, p.ProofMessageID,
+	)
+	if err != nil {
+		return p, err
+	}
+	id, _ := res.LastInsertId()
+	p.ID = id
+	return p, nil
+}
+
+// UpdatePunishment updates status, proof and progress of punishment
+func (m *sqlDB) UpdatePunishment(p model.Punishment) (model.Punishment, error) {
+	_, err := m.conn.Exec(
+		"UPDATE `punishments` SET status=?, proofmessageid=?, done=? WHERE id=?",
+		p.Status, p.ProofMessageID, p.Done, p.ID,
+	)
+	if err != nil {
+		return p, err
+	}
+	return m.SelectPunishment(p.ID)
+}
+
+// SelectPunishment selects punishments ledger entry
+func (m *sqlDB) SelectPunishment(id int64) (model.Punishment, error) {
+	var p model.Punishment
+	err := m.conn.Get(&p, "SELECT * FROM `punishments` WHERE id=?", id)
+	return p, err
+}
+
+// FindPunishmentByMessage finds punishment announced by the bot's message
+func (m *sqlDB) FindPunishmentByMessage(groupID int64, messageID int) (model.Punishment, error) {
+	var p model.Punishment
+	err := m.conn.Get(&p, "SELECT * FROM `punishments` WHERE groupid=? AND messageid=?", groupID, messageID)
+	return p, err
+}
+
+// ListPunishments returns punishments of a group ordered by issue time
+func (m *sqlDB) ListPunishments(groupID int64) ([]model.Punishment, error) {
+	items := []model.Punishment{}
+	err := m.conn.Select(&items, "SELECT * FROM `punishments` WHERE groupid=? ORDER BY issued, id", groupID)
+	return items, err
+}
+
+// SetMessage creates or replaces message override of a group
+func (m *sqlDB) SetMessage(msg model.Message) error {
+	tx, err := m.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM `messages` WHERE groupid=? AND msgkey=?", msg.GroupID, msg.Key); err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec(
+		"INSERT INTO `messages` (groupid, msgkey, text) VALUES (?, ?, ?)",
+		msg.GroupID, msg.Key, msg.Text,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteMessage deletes message override of a group
+func (m *sqlDB) DeleteMessage(groupID int64, key string) error {
+	_, err := m.conn.Exec("DELETE FROM `messages` WHERE groupid=? AND msgkey=?", groupID, key)
+	return err
+}
+
+// ListMessages returns message overrides of a group ordered by key
+func (m *sqlDB) ListMessages(groupID int64) ([]model.Message, error) {
+	items := []model.Message{}
+	err := m.conn.Select(&items, "SELECT * FROM `messages` WHERE groupid=? ORDER BY msgkey", groupID)
+	return items, err
+}
@@ -0,0 +1,280 @@
+// Package telegramtest provides a fake Telegram Bot API server for tests.
+// It records every call the bot makes and lets tests inject updates,
+// so whole conversations can be checked without network.
+package telegramtest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// longPollLimit caps getUpdates long polling so Close never waits long
+const longPollLimit = 100 * time.Millisecond
+
+// Request is a recorded Bot API call
+type Request struct {
+	Method string
+	Params url.Values
+	// Files holds uploaded file contents by form field name
+	Files map[string][]byte
+}
+
+// ChatID returns chat_id parameter of the request
+func (r Request) ChatID() int64 {
+	id, _ := strconv.ParseInt(r.Params.Get("chat_id"), 10, 64)
+	return id
+}
+
+// Server is a fake Telegram Bot API
+type Server struct {
+	*httptest.Server
+	Token string
+	Self  tgbotapi.User
+
+	mu        sync.Mutex
+	requests  []Request
+	updates   []tgbotapi.Update
+	lastID    int
+	messageID int
+	admins    map[int64][]tgbotapi.ChatMember
+	notify    chan struct{}
+}
+
+// NewServer starts a fake Bot API for a bot with given username
+func NewServer(username string) *Server {
+	s := &Server{
+		Token:  "testToken",
+		Self:   tgbotapi.User{ID: 1000, FirstName: username, UserName: username},
+		admins: map[int64][]tgbotapi.ChatMember{},
+		notify: make(chan struct{}, 1),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Client returns http client that sends api.telegram.org requests to s
+func (s *Server) Client() *http.Client {
+	target, _ := url.Parse(s.URL)
+	return &http.Client{Transport: rewriteTransport{target}}
+}
+
+// NewBotAPI creates tgbotapi client talking to s
+func (s *Server) NewBotAPI() (*tgbotapi.BotAPI, error) {
+	return tgbotapi.NewBotAPIWithClient(s.Token, s.Client())
+}
+
+// SetAdmins sets users returned by getChatAdministrators for chat
+func (s *Server) SetAdmins(chatID int64, users ...tgbotapi.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	members := []tgbotapi.ChatMember{}
+	for i := range users {
+		members = append(members, tgbotapi.ChatMember{
+			User:   &users[i],
+			Status: "administrator",
+		})
+	}
+	s.admins[chatID] = members
+}
+
+// Inject queues update for the next getUpdates call. UpdateID is assigned
+// automatically when it is zero.
+func (s *Server) Inject(u tgbotapi.Update) {
+	s.mu.Lock()
+	if u.UpdateID == 0 {
+		s.lastID++
+		u.UpdateID = s.lastID
+	} else if u.UpdateID > s.lastID {
+		s.lastID = u.UpdateID
+	}
+	s.updates = append(s.updates, u)
+	s.mu.Unlock()
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Requests returns all recorded calls with given method,
+// or every call when method is empty
+func (s *Server) Requests(method string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := []Request{}
+	for _, r := range s.requests {
+		if method == "" || r.Method == method {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Messages returns texts sent with sendMessage to chatID
+func (s *Server) Messages(chatID int64) []string {
+	texts := []string{}
+	for _, r := range s.Requests("sendMessage") {
+		if r.ChatID() == chatID {
+			texts = append(texts, r.Params.Get("text"))
+		}
+	}
+	return texts
+}
+
+// WaitRequests waits until at least n calls with method were recorded
+func (s *Server) WaitRequests(method string, n int, timeout time.Duration) []Request {
+	deadline := time.Now().Add(timeout)
+	for {
+		reqs := s.Requests(method)
+		if len(reqs) >= n || time.Now().After(deadline) {
+			return reqs
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Reset forgets recorded calls
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	prefix := "/bot" + s.Token + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		reply(w, http.StatusUnauthorized, false, nil, "Unauthorized")
+		return
+	}
+	method := strings.TrimPrefix(r.URL.Path, prefix)
+
+	req := Request{Method: method, Files: map[string][]byte{}}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			reply(w, http.StatusBadRequest, false, nil, err.Error())
+			return
+		}
+		req.Params = url.Values(r.MultipartForm.Value)
+		for field, headers := range r.MultipartForm.File {
+			f, err := headers[0].Open()
+			if err != nil {
+				continue
+			}
+			req.Files[field], _ = ioutil.ReadAll(f)
+			f.Close()
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			reply(w, http.StatusBadRequest, false, nil, err.Error())
+			return
+		}
+		req.Params = r.PostForm
+	}
+
+	if method == "getUpdates" {
+		reply(w, http.StatusOK, true, s.getUpdates(req.Params), "")
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	switch method {
+	case "getMe":
+		reply(w, http.StatusOK, true, s.Self, "")
+	case "getChatAdministrators":
+		s.mu.Lock()
+		admins := s.admins[req.ChatID()]
+		s.mu.Unlock()
+		if admins == nil {
+			admins = []tgbotapi.ChatMember{}
+		}
+		reply(w, http.StatusOK, true, admins, "")
+	case "sendMessage", "forwardMessage", "sendDocument", "sendPhoto":
+		s.mu.Lock()
+		s.messageID++
+		msg := tgbotapi.Message{
+			MessageID: s.messageID,
+			From:      &s.Self,
+			Date:      int(time.Now().Unix()),
+			Chat:      &tgbotapi.Chat{ID: req.ChatID()},
+			Text:      req.Params.Get("text"),
+		}
+		s.mu.Unlock()
+		reply(w, http.StatusOK, true, msg, "")
+	case "editMessageText":
+		id, _ := strconv.Atoi(req.Params.Get("message_id"))
+		msg := tgbotapi.Message{
+			MessageID: id,
+			From:      &s.Self,
+			Date:      int(time.Now().Unix()),
+			Chat:      &tgbotapi.Chat{ID: req.ChatID()},
+			Text:      req.Params.Get("text"),
+		}
+		reply(w, http.StatusOK, true, msg, "")
+	default:
+		reply(w, http.StatusOK, true, true, "")
+	}
+}
+
+func (s *Server) getUpdates(params url.Values) []tgbotapi.Update {
+	offset, _ := strconv.Atoi(params.Get("offset"))
+	timeout := time.NewTimer(longPollLimit)
+	defer timeout.Stop()
+	for {
+		s.mu.Lock()
+		res := []tgbotapi.Update{}
+		for _, u := range s.updates {
+			if u.UpdateID >= offset {
+				res = append(res, u)
+			}
+		}
+		s.mu.Unlock()
+		if len(res) > 0 {
+			return res
+		}
+		select {
+		case <-s.notify:
+		case <-timeout.C:
+			return res
+		}
+	}
+}
+
+func reply(w http.ResponseWriter, status int, ok bool, result interface{}, description string) {
+	resp := map[string]interface{}{"ok": ok}
+	if ok {
+		resp["result"] = result
+	} else {
+		resp["description"] = description
+		resp["error_code"] = status
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// rewriteTransport sends every request to target keeping its path
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r2 := new(http.Request)
+	*r2 = *r
+	u := *r.URL
+	u.Scheme = t.target.Scheme
+	u.Host = t.target.Host
+	r2.URL = &u
+	r2.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(r2)
+}